@@ -20,11 +20,15 @@
 package console
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"os"
+	"os/signal"
 	"sort"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/symfony-cli/terminal"
@@ -50,6 +54,10 @@ type Application struct {
 	Flags []Flag
 	// Prefix used to automatically find flag in environment
 	FlagEnvPrefix []string
+	// ConfigSources provide flag values from configuration files, they are
+	// applied below environment variables and above defaults, later sources
+	// taking precedence over the previous ones
+	ConfigSources []ConfigSource
 	// Categories contains the categorized commands and is populated on app startup
 	Categories CommandCategories
 	// An action to execute before any subcommands are run, but after the context is ready
@@ -60,6 +68,14 @@ type Application struct {
 	After AfterFunc
 	// The action to execute when no subcommands are specified
 	Action ActionFunc
+	// Middleware wraps the action of every command, the first one being the
+	// outermost
+	Middleware []MiddlewareFunc
+	// Execute this function if the proper command cannot be found, it
+	// receives the name typed by the user. By default, a CommandNotFoundError
+	// listing similar command names is returned, return
+	// ShowCommandHelp(c, name) to fall back to this behavior.
+	CommandNotFound CommandNotFoundFunc
 	// Build date
 	BuildDate string
 	// Copyright of the binary if any
@@ -68,25 +84,126 @@ type Application struct {
 	Writer io.Writer
 	// ErrWriter writes error output
 	ErrWriter io.Writer
-
-	setupOnce sync.Once
+	// EventDispatcher notifies listeners of the lifecycle events of the
+	// application (CommandEvent, ErrorEvent, TerminateEvent and SignalEvent)
+	EventDispatcher *EventDispatcher
+	// CommandLoader lists commands which are only built when needed
+	CommandLoader CommandLoader
+	// PluginPrefix enables external plugin commands: executables found in
+	// $PATH whose name starts with this prefix (e.g. "symfony-") are
+	// registered as commands when no built-in command has the same name
+	PluginPrefix string
+	// EnableShell registers the self:shell command, which runs the
+	// application commands from an interactive shell
+	EnableShell bool
+	// DisableSignalHandling prevents the application from canceling the
+	// execution context when receiving SIGINT or SIGTERM
+	DisableSignalHandling bool
+	// DisableFlagAbbreviations requires flags to be typed in full, instead of
+	// accepting unambiguous prefixes like --verb for --verbose
+	DisableFlagAbbreviations bool
+	// HelpFlag prints the help, defaults to the HelpFlag global.
+	// Set to a flag with an empty name to disable it.
+	HelpFlag *BoolFlag
+	// VersionFlag prints the version, defaults to the VersionFlag global.
+	// Set to a flag with an empty name to disable it.
+	VersionFlag *BoolFlag
+	// QuietFlag silences the output, defaults to the QuietFlag global.
+	// Set to a flag with an empty name to disable it.
+	QuietFlag Flag
+	// LogLevelFlag sets the verbosity, defaults to the LogLevelFlag global.
+	// Set to a flag with an empty name to disable it.
+	LogLevelFlag Flag
+	// HelpCommand displays the help, defaults to a copy of the built-in one
+	HelpCommand *Command
+	// VersionCommand displays the version, defaults to a copy of the
+	// built-in one
+	VersionCommand *Command
+	// HelpPrinter writes the help output, defaults to the HelpPrinter global
+	HelpPrinter func(w io.Writer, templ string, data interface{})
+	// VersionPrinter prints the version, defaults to the VersionPrinter global
+	VersionPrinter func(*Context)
+	// AppHelpTemplate is the text template for the application help,
+	// defaults to the AppHelpTemplate global
+	AppHelpTemplate string
+	// CategoryHelpTemplate is the text template for the category help,
+	// defaults to the CategoryHelpTemplate global
+	CategoryHelpTemplate string
+	// CommandHelpTemplate is the text template for the command help,
+	// defaults to the CommandHelpTemplate global
+	CommandHelpTemplate string
+
+	setupOnce   sync.Once
+	config      []loadedConfig
+	sensitive   sensitiveValues
+	pluginsOnce sync.Once
 }
 
 // Run is the entry point to the cli app. Parses the arguments slice and routes
 // to the proper flag/args combination
-func (a *Application) Run(arguments []string) (err error) {
+func (a *Application) Run(arguments []string) error {
+	return a.RunContext(context.Background(), arguments)
+}
+
+// RunContext is like Run but the given context.Context is made available to
+// actions via Context.Context(). Unless DisableSignalHandling is set, this
+// context is canceled when SIGINT or SIGTERM is received, a second signal
+// forces the application to exit.
+//
+// If an error occurs, it is displayed and OsExiter is called with the
+// corresponding exit code once the execution is over.
+func (a *Application) RunContext(ctx context.Context, arguments []string) error {
+	exitCode, err := a.ExecuteContext(ctx, arguments)
+	handleError(err, &a.sensitive)
+	if err != nil || exitCode != 0 {
+		OsExiter(exitCode)
+	}
+	return err
+}
+
+// Execute is like Run but never displays errors nor exits the process: the
+// exit code is returned along with the error instead.
+func (a *Application) Execute(arguments []string) (int, error) {
+	return a.ExecuteContext(context.Background(), arguments)
+}
+
+// ExecuteContext is like RunContext but never displays errors nor exits the
+// process: the exit code is returned along with the error instead.
+func (a *Application) ExecuteContext(ctx context.Context, arguments []string) (exitCode int, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	appCtx := NewContext(a, nil, nil)
+	appCtx.ctx = ctx
+
 	defer func() {
 		if e := recover(); e != nil {
-			HandleExitCoder(WrapPanic(e))
+			err = WrapPanic(e)
 		}
+		exitCode, err = a.dispatchTerminate(appCtx, err)
 	}()
 
 	a.setupOnce.Do(func() {
 		a.setup()
 	})
 
-	context := NewContext(a, nil, nil)
-	context.flagSet, err = a.parseArgs(arguments[1:])
+	if !a.DisableSignalHandling {
+		appCtx.stopSignals = a.handleSignals(appCtx, cancel)
+		defer appCtx.stopSignals()
+	}
+
+	return 0, a.run(appCtx, arguments)
+}
+
+func (a *Application) run(context *Context, arguments []string) (err error) {
+	if err := a.loadConfig(); err != nil {
+		return err
+	}
+
+	context.flagSet, context.flagSources, err = a.parseArgs(arguments[1:])
+	if context.parentContext != nil {
+		inheritFlagValues(context)
+	}
 
 	a.configureIO(context)
 
@@ -98,7 +215,6 @@ func (a *Application) Run(arguments []string) (err error) {
 		err = IncorrectUsageError{err}
 		_ = ShowAppHelp(context)
 		fmt.Fprintln(a.Writer)
-		HandleExitCoder(err)
 		return err
 	}
 
@@ -117,11 +233,17 @@ func (a *Application) Run(arguments []string) (err error) {
 	args := context.Args()
 	if args.Present() {
 		name := args.first()
-		context.Command, err = a.BestCommand(name)
+		command, err := a.BestCommand(name)
 		if err != nil {
-			HandleExitCoder(err)
 			return err
 		}
+		if command == nil && a.PluginPrefix != "" {
+			a.loadPlugins()
+			if command, err = a.BestCommand(name); err != nil {
+				return err
+			}
+		}
+		context.setCommand(command)
 	}
 
 	if a.Before != nil {
@@ -129,16 +251,13 @@ func (a *Application) Run(arguments []string) (err error) {
 		if beforeErr != nil {
 			fmt.Fprintf(a.Writer, "%v\n\n", beforeErr)
 			_ = ShowAppHelp(context)
-			HandleExitCoder(beforeErr)
 			err = beforeErr
 			return err
 		}
 	}
 
 	if checkHelp(context) {
-		err := ShowAppHelpAction(context)
-		HandleExitCoder(err)
-		return err
+		return ShowAppHelpAction(context)
 	}
 
 	if checkVersion(context) {
@@ -148,13 +267,19 @@ func (a *Application) Run(arguments []string) (err error) {
 
 	if c := context.Command; c != nil {
 		err = c.Run(context)
+	} else if name := args.first(); name != "" && a.CommandNotFound != nil && len(a.categoriesWithPrefix(name)) == 0 {
+		err = a.CommandNotFound(context, name)
 	} else {
 		err = a.Action(context)
 	}
-	HandleExitCoder(err)
 	return err
 }
 
+// Use registers middleware wrapping the action of every command
+func (a *Application) Use(middleware ...MiddlewareFunc) {
+	a.Middleware = append(a.Middleware, middleware...)
+}
+
 // MustRun is the entry point to the CLI app. Parses the arguments slice and routes
 // to the proper flag/args combination. Under the hood it calls `Run` but will panic
 // if any error happen
@@ -167,7 +292,7 @@ func (a *Application) MustRun(arguments []string) {
 // Command returns the named command on App. Returns nil if the command does not
 // exist
 func (a *Application) Command(name string) *Command {
-	for _, c := range a.Commands {
+	for _, c := range a.allCommands() {
 		if c.HasName(name, true) {
 			c.UserName = name
 			return c
@@ -187,7 +312,7 @@ func (a *Application) BestCommand(name string) (*Command, error) {
 
 	// fuzzy match?
 	var matches []*Command
-	for _, c := range a.Commands {
+	for _, c := range a.allCommands() {
 		if c.HasName(name, false) {
 			matches = append(matches, c)
 		}
@@ -233,11 +358,24 @@ func (a *Application) VisibleCategories() []CommandCategory {
 	return ret
 }
 
-// VisibleCommands returns a slice of the Commands with Hidden=false
+// categoriesWithPrefix returns the visible categories whose name starts with
+// the given prefix
+func (a *Application) categoriesWithPrefix(prefix string) []CommandCategory {
+	categories := []CommandCategory{}
+	for _, c := range a.VisibleCategories() {
+		if strings.HasPrefix(c.Name(), prefix) {
+			categories = append(categories, c)
+		}
+	}
+	return categories
+}
+
+// VisibleCommands returns a slice of the Commands with Hidden=false, including
+// subcommands
 func (a *Application) VisibleCommands() []*Command {
 	ret := []*Command{}
-	for _, command := range a.Commands {
-		if command.Hidden == nil || !command.Hidden() {
+	for _, command := range a.allCommands() {
+		if !command.isHidden() {
 			ret = append(ret, command)
 		}
 	}
@@ -254,9 +392,24 @@ func (a *Application) VisibleFlags() []Flag {
 	return visibleFlags(a.Flags)
 }
 
+// allCommands returns the commands of the App, including nested subcommands
+func (a *Application) allCommands() []*Command {
+	commands := []*Command{}
+	walkCommands(a.Commands, func(c *Command) {
+		commands = append(commands, c)
+	})
+	return commands
+}
+
 // setup runs initialization code to ensure all data structures are ready for
 // `Run` or inspection prior to `Run`.
 func (a *Application) setup() {
+	walkCommands(a.Commands, func(c *Command) {
+		for _, sub := range c.Subcommands {
+			sub.parent = c
+		}
+	})
+
 	if a.BuildDate == "" {
 		a.BuildDate = time.Now().Format(time.RFC3339)
 	}
@@ -281,8 +434,27 @@ func (a *Application) setup() {
 		a.Channel = "dev"
 	}
 
+	if a.HelpFlag == nil {
+		a.HelpFlag = HelpFlag
+	}
+	if a.VersionFlag == nil {
+		a.VersionFlag = VersionFlag
+	}
+	if a.QuietFlag == nil && QuietFlag != nil {
+		a.QuietFlag = QuietFlag
+	}
+	if a.LogLevelFlag == nil && LogLevelFlag != nil {
+		a.LogLevelFlag = LogLevelFlag
+	}
+	if a.HelpCommand == nil {
+		a.HelpCommand = helpCommand.clone()
+	}
+	if a.VersionCommand == nil {
+		a.VersionCommand = versionCommand.clone()
+	}
+
 	if a.Action == nil {
-		a.Action = helpCommand.Action
+		a.Action = a.HelpCommand.Action
 	}
 
 	if a.Writer == nil {
@@ -292,14 +464,19 @@ func (a *Application) setup() {
 		a.ErrWriter = terminal.Stderr
 	}
 
-	a.prependFlag(VersionFlag)
+	if a.VersionFlag != nil && a.VersionFlag.Name != "" {
+		a.prependFlag(a.VersionFlag)
+	}
 
-	if LogLevelFlag != nil && LogLevelFlag.Name != "" {
-		a.prependFlag(LogLevelFlag)
+	if a.LogLevelFlag != nil && flagName(a.LogLevelFlag) != "" {
+		a.prependFlag(a.LogLevelFlag)
 	}
 
-	if QuietFlag != nil && QuietFlag.Name != "" {
-		a.prependFlag(QuietFlag.ForApp(a))
+	if a.QuietFlag != nil && flagName(a.QuietFlag) != "" {
+		if f, ok := a.QuietFlag.(*quietFlag); ok {
+			a.QuietFlag = f.ForApp(a)
+		}
+		a.prependFlag(a.QuietFlag)
 	}
 
 	if NoInteractionFlag != nil && NoInteractionFlag.Name != "" {
@@ -310,46 +487,94 @@ func (a *Application) setup() {
 		a.prependFlag(AnsiFlag)
 	}
 
-	if NoAnsiFlag != nil && NoAnsiFlag.Name != "" {
-		a.prependFlag(NoAnsiFlag)
-	}
-
-	if a.Command(helpCommand.Name) == nil && (helpCommand.Hidden == nil || !helpCommand.Hidden()) {
-		a.Commands = append([]*Command{helpCommand}, a.Commands...)
-		// This command is global and as such is mutated by tests so we reset
-		// the flags to ensure a consistent behaviour
-		helpCommand.Flags = nil
+	if a.Command(a.HelpCommand.Name) == nil && !a.HelpCommand.isHidden() {
+		a.Commands = append([]*Command{a.HelpCommand}, a.Commands...)
 	}
 
-	if a.Command(versionCommand.Name) == nil && (versionCommand.Hidden == nil || !versionCommand.Hidden()) {
-		a.Commands = append([]*Command{versionCommand}, a.Commands...)
-		// This command is global and as such is mutated by tests so we reset
-		// the flags to ensure a consistent behaviour
-		helpCommand.Flags = nil
+	if a.Command(a.VersionCommand.Name) == nil && !a.VersionCommand.isHidden() {
+		a.Commands = append([]*Command{a.VersionCommand}, a.Commands...)
 	}
 
-	if HelpFlag != nil {
-		a.prependFlag(HelpFlag)
+	if a.HelpFlag != nil && a.HelpFlag.Name != "" {
+		a.prependFlag(a.HelpFlag)
 	}
 
 	registerAutocompleteCommands(a)
+	registerShellCommand(a)
+	registerLazyCommands(a)
 
-	for _, c := range a.Commands {
-		c.normalizeCommandNames()
-		if c.HelpName == "" {
-			c.HelpName = fmt.Sprintf("%s %s", a.HelpName, c.FullName())
-		}
-		checkFlagsUnicity(a.Flags, c.Flags, c.FullName())
-		checkArgsModes(c.Args)
+	checkShortFlagsClusters(a.Flags, "")
+	commands := a.allCommands()
+	for _, c := range commands {
+		a.setupCommand(c)
 	}
 
 	a.Categories = newCommandCategories()
-	for _, command := range a.Commands {
-		a.Categories.AddCommand(command.Category, command)
+	for _, command := range commands {
+		a.Categories.AddCommand(command.namespace(), command)
 	}
 	sort.Sort(a.Categories.(*commandCategories))
 }
 
+// handleSignals dispatches SignalEvent and cancels the execution context on
+// the first SIGINT or SIGTERM and exits on the second one. The returned
+// function must be called to stop listening for signals, it can be called
+// several times.
+func (a *Application) handleSignals(ctx *Context, cancel context.CancelFunc) func() {
+	signals := make(chan os.Signal, 2)
+	done := make(chan struct{})
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	dispatch := func(sig os.Signal) *Event {
+		e := newEvent(SignalEvent, ctx)
+		e.Signal = sig
+		e.exitCode = 1
+		if s, ok := sig.(syscall.Signal); ok {
+			e.exitCode = 128 + int(s)
+		}
+		a.EventDispatcher.Dispatch(e)
+		return e
+	}
+
+	go func() {
+		select {
+		case sig := <-signals:
+			dispatch(sig)
+			cancel()
+		case <-done:
+			return
+		}
+
+		select {
+		case sig := <-signals:
+			OsExiter(dispatch(sig).ExitCode())
+		case <-done:
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+		})
+	}
+}
+
+// setupCommand ensures the command is ready to be run
+func (a *Application) setupCommand(c *Command) {
+	c.normalizeCommandNames()
+	c.bindOptions()
+	if c.HelpName == "" {
+		c.HelpName = fmt.Sprintf("%s %s", a.HelpName, c.FullName())
+	}
+	checkFlagsUnicity(append(append([]Flag{}, a.Flags...), c.inheritedFlags()...), c.Flags, c.FullName())
+	c.strictFlags = a.DisableFlagAbbreviations
+	checkShortFlagsClusters(c.definedFlags(), c.FullName())
+	checkArgsModes(c.Args)
+	checkFlagGroupsDefinition(c)
+}
+
 func (a *Application) prependFlag(fl Flag) {
 	if !hasFlag(a.Flags, fl) {
 		a.Flags = append([]Flag{fl}, a.Flags...)
@@ -21,6 +21,7 @@ package console
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"flag"
 	"fmt"
@@ -28,8 +29,10 @@ import (
 	"log"
 	"os"
 	"reflect"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/symfony-cli/terminal"
 	. "gopkg.in/check.v1"
@@ -428,9 +431,11 @@ func (ts *ApplicationSuite) TestApp_VisibleCommands(c *C) {
 
 	helpCommand.Hidden = Hide
 	versionCommand.Hidden = Hide
+	shellCommand.Hidden = Hide
 	defer func() {
 		helpCommand.Hidden = nil
 		versionCommand.Hidden = nil
+		shellCommand.Hidden = nil
 	}()
 
 	app.setup()
@@ -772,6 +777,210 @@ func TestApp_AfterFunc(t *testing.T) {
 	}
 }
 
+func TestApp_RunContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	afterCalled := false
+	app := &Application{
+		Commands: []*Command{
+			{
+				Name: "sub",
+				Action: func(c *Context) error {
+					<-c.Context().Done()
+					return c.Context().Err()
+				},
+				After: func(c *Context) error {
+					afterCalled = true
+					return nil
+				},
+			},
+		},
+	}
+
+	err := app.RunContext(ctx, []string{"command", "sub"})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context to be canceled, got %v", err)
+	}
+	if !afterCalled {
+		t.Errorf("After() not executed when expected")
+	}
+}
+
+func TestApp_RunContext_Signal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sending signals is not supported on Windows")
+	}
+
+	app := &Application{
+		Action: func(c *Context) error {
+			p, err := os.FindProcess(os.Getpid())
+			if err != nil {
+				return err
+			}
+			if err := p.Signal(os.Interrupt); err != nil {
+				return err
+			}
+
+			select {
+			case <-c.Context().Done():
+				return nil
+			case <-time.After(5 * time.Second):
+				return errors.New("context not canceled on SIGINT")
+			}
+		},
+	}
+
+	if err := app.Run([]string{"command"}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestApp_CommandNotFound(t *testing.T) {
+	notFound := []string{}
+	app := &Application{
+		Writer: io.Discard,
+		Commands: []*Command{
+			{Name: "bar", Category: "foo", Action: func(c *Context) error { return nil }},
+		},
+		CommandNotFound: func(c *Context, name string) error {
+			notFound = append(notFound, name)
+			return nil
+		},
+	}
+
+	for _, args := range [][]string{{"app", "foo:bar"}, {"app", "foo"}, {"app", "baz"}, {"app", "qux", "--", "arg"}} {
+		if err := app.Run(args); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	}
+	if expected := []string{"baz", "qux"}; !reflect.DeepEqual(notFound, expected) {
+		t.Errorf("expected CommandNotFound to be called with %v, got %v", expected, notFound)
+	}
+
+	app = &Application{Writer: io.Discard}
+	err := app.Run([]string{"app", "baz"})
+	if _, ok := err.(*CommandNotFoundError); !ok {
+		t.Errorf("expected a CommandNotFoundError, got %v", err)
+	}
+
+	app = &Application{
+		Writer: io.Discard,
+		CommandNotFound: func(c *Context, name string) error {
+			return ShowCommandHelp(c, name)
+		},
+	}
+	err = app.Run([]string{"app", "baz"})
+	if _, ok := err.(*CommandNotFoundError); !ok {
+		t.Errorf("expected ShowCommandHelp to return a CommandNotFoundError, got %v", err)
+	}
+}
+
+func TestApp_Execute(t *testing.T) {
+	oldExiter := OsExiter
+	defer func() { OsExiter = oldExiter }()
+	OsExiter = func(rc int) {
+		t.Fatalf("OsExiter should not be called, got %d", rc)
+	}
+
+	afterCalled := false
+	app := &Application{
+		Writer: io.Discard,
+		Commands: []*Command{
+			{
+				Name: "fail",
+				Action: func(c *Context) error {
+					return Exit("failure", 9)
+				},
+				After: func(c *Context) error {
+					afterCalled = true
+					return nil
+				},
+			},
+			{
+				Name: "panic",
+				Action: func(c *Context) error {
+					panic("boom")
+				},
+			},
+			{
+				Name:   "ok",
+				Action: func(c *Context) error { return nil },
+			},
+		},
+	}
+
+	exitCode, err := app.Execute([]string{"app", "fail"})
+	if err == nil || err.Error() != "failure" || exitCode != 9 {
+		t.Errorf("expected exit code 9 and error, got %d and %v", exitCode, err)
+	}
+	if !afterCalled {
+		t.Errorf("After() not executed when expected")
+	}
+
+	exitCode, err = app.Execute([]string{"app", "panic"})
+	if _, ok := err.(WrappedPanic); !ok || exitCode != 1 {
+		t.Errorf("expected exit code 1 and a wrapped panic, got %d and %v", exitCode, err)
+	}
+
+	exitCode, err = app.Execute([]string{"app", "ok"})
+	if err != nil || exitCode != 0 {
+		t.Errorf("expected exit code 0 and no error, got %d and %v", exitCode, err)
+	}
+
+	exitCode, err = app.Execute([]string{"app", "unknown"})
+	if _, ok := err.(*CommandNotFoundError); !ok || exitCode != 3 {
+		t.Errorf("expected exit code 3 and a CommandNotFoundError, got %d and %v", exitCode, err)
+	}
+}
+
+func TestApp_ScopedConfiguration(t *testing.T) {
+	for i := 0; i < 4; i++ {
+		i := i
+		t.Run(fmt.Sprintf("app%d", i), func(t *testing.T) {
+			t.Parallel()
+
+			output := new(bytes.Buffer)
+			helpPrinted := false
+			app := &Application{
+				Writer:   output,
+				HelpFlag: &BoolFlag{Name: fmt.Sprintf("aide%d", i)},
+				HelpPrinter: func(w io.Writer, templ string, data interface{}) {
+					helpPrinted = true
+					printHelp(w, templ, data)
+				},
+				AppHelpTemplate:     "custom app help\n",
+				CommandHelpTemplate: "custom command help\n",
+				Commands: []*Command{
+					{Name: "foo", Action: func(c *Context) error { return nil }},
+				},
+			}
+
+			if err := app.Run([]string{"app", fmt.Sprintf("--aide%d", i)}); err != nil {
+				t.Fatal(err)
+			}
+			if output.String() != "custom app help\n" || !helpPrinted {
+				t.Errorf("expected custom help to be displayed, got %q", output.String())
+			}
+
+			output.Reset()
+			if err := app.Run([]string{"app", "help", "foo"}); err != nil {
+				t.Fatal(err)
+			}
+			if output.String() != "custom command help\n" {
+				t.Errorf("expected custom command help to be displayed, got %q", output.String())
+			}
+
+			if app.HelpCommand == helpCommand || app.Command("help") != app.HelpCommand {
+				t.Errorf("expected the help command to be scoped to the application")
+			}
+			if app.Command("help").Flags[0] != app.HelpFlag {
+				t.Errorf("expected the help command to use the application help flag")
+			}
+		})
+	}
+}
+
 func TestAppNoHelpFlag(t *testing.T) {
 	oldFlag := HelpFlag
 	defer func() {
@@ -1049,9 +1258,11 @@ func TestApp_Run_Categories(t *testing.T) {
 	}
 	helpCommand.Hidden = Hide
 	versionCommand.Hidden = Hide
+	shellCommand.Hidden = Hide
 	defer func() {
 		helpCommand.Hidden = nil
 		versionCommand.Hidden = nil
+		shellCommand.Hidden = nil
 	}()
 
 	if err := app.Run([]string{"categories"}); err != nil {
@@ -1111,9 +1322,11 @@ func (ts *ApplicationSuite) TestApp_VisibleCategories(c *C) {
 
 	helpCommand.Hidden = Hide
 	versionCommand.Hidden = Hide
+	shellCommand.Hidden = Hide
 	defer func() {
 		helpCommand.Hidden = nil
 		versionCommand.Hidden = nil
+		shellCommand.Hidden = nil
 	}()
 
 	expected := []CommandCategory{
@@ -19,6 +19,13 @@
 
 package console
 
+import (
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
 type Args interface {
 	// Get returns the named argument, or else a blank string
 	Get(name string) string
@@ -31,8 +38,17 @@ type Args interface {
 	Len() int
 	// Present checks if there are any arguments present
 	Present() bool
-	// Slice returns a copy of the internal slice
-	Slice() []string
+	// Slice returns a copy of the internal slice, or the values of the named
+	// argument when a name is given
+	Slice(name ...string) []string
+	// Int returns the named argument as an int, 0 if not set
+	Int(name string) (int, error)
+	// Float64 returns the named argument as a float64, 0 if not set
+	Float64(name string) (float64, error)
+	// Bool returns the named argument as a bool, false if not set
+	Bool(name string) (bool, error)
+	// Duration returns the named argument as a time.Duration, 0 if not set
+	Duration(name string) (time.Duration, error)
 }
 
 type args struct {
@@ -99,8 +115,84 @@ func (a *args) Present() bool {
 	return a.Len() != 0
 }
 
-func (a *args) Slice() []string {
+func (a *args) Slice(name ...string) []string {
+	if len(name) > 0 {
+		return a.named(name[0])
+	}
+
 	ret := make([]string, len(a.values))
 	copy(ret, a.values)
 	return ret
 }
+
+// named returns the values of the named argument
+func (a *args) named(name string) []string {
+	if a.command == nil {
+		return nil
+	}
+	for _, arg := range a.command.Args {
+		if arg.Name != name {
+			continue
+		}
+		if arg.Slice {
+			return a.Tail()
+		}
+		if v := a.Get(name); v != "" {
+			return []string{v}
+		}
+		return []string{}
+	}
+	return nil
+}
+
+func (a *args) Int(name string) (int, error) {
+	v := a.Get(name)
+	if v == "" {
+		return 0, nil
+	}
+	i, err := strconv.ParseInt(v, 0, strconv.IntSize)
+	if err != nil {
+		return 0, invalidArgValue(name, v)
+	}
+	return int(i), nil
+}
+
+func (a *args) Float64(name string) (float64, error) {
+	v := a.Get(name)
+	if v == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, invalidArgValue(name, v)
+	}
+	return f, nil
+}
+
+func (a *args) Bool(name string) (bool, error) {
+	v := a.Get(name)
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, invalidArgValue(name, v)
+	}
+	return b, nil
+}
+
+func (a *args) Duration(name string) (time.Duration, error) {
+	v := a.Get(name)
+	if v == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, invalidArgValue(name, v)
+	}
+	return d, nil
+}
+
+func invalidArgValue(name, value string) error {
+	return errors.Errorf(`invalid value "%s" for argument "%s"`, value, name)
+}
@@ -25,6 +25,7 @@ import (
 	"strings"
 
 	"github.com/pkg/errors"
+	"github.com/posener/complete"
 )
 
 type ArgDefinition []*Arg
@@ -61,6 +62,10 @@ type Arg struct {
 	Description   string
 	Optional      bool
 	Slice         bool
+	// ArgsPredictor provides the shell completion suggestions of the argument
+	ArgsPredictor func(*Context, complete.Args) []string
+	// Validator is called for each value given for the argument
+	Validator func(*Context, string) error
 }
 
 func (a *Arg) String() string {
@@ -79,20 +84,26 @@ func (a *Arg) String() string {
 }
 
 func checkArgsModes(args []*Arg) {
+	if err := validateArgsModes(args); err != nil {
+		panic(err.Error())
+	}
+}
+
+func validateArgsModes(args []*Arg) error {
 	arguments := make(map[string]bool)
 	hasSliceArgument := false
 	hasOptional := false
 
 	for _, arg := range args {
 		if arguments[arg.Name] {
-			panic(fmt.Sprintf(`An argument with name "%s" already exists.`, arg.Name))
+			return errors.Errorf(`An argument with name "%s" already exists.`, arg.Name)
 		}
 
 		if hasSliceArgument {
-			panic("Cannot add an argument after an array argument.")
+			return errors.New("Cannot add an argument after an array argument.")
 		}
 		if !arg.Optional && hasOptional {
-			panic("Cannot add a required argument after an optional one.")
+			return errors.New("Cannot add a required argument after an optional one.")
 		}
 
 		if arg.Slice {
@@ -104,6 +115,8 @@ func checkArgsModes(args []*Arg) {
 
 		arguments[arg.Name] = true
 	}
+
+	return nil
 }
 
 func checkRequiredArgs(command *Command, context *Context) error {
@@ -138,5 +151,16 @@ func checkRequiredArgs(command *Command, context *Context) error {
 		return errors.New("Too many arguments")
 	}
 
+	for _, arg := range command.Args {
+		if arg.Validator == nil {
+			continue
+		}
+		for _, value := range args.Slice(arg.Name) {
+			if err := arg.Validator(context, value); err != nil {
+				return errors.Wrapf(err, `invalid value for argument "%s"`, arg.Name)
+			}
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,167 @@
+/*
+ * Copyright (c) 2021-present Fabien Potencier <fabien@symfony.com>
+ *
+ * This file is part of Symfony CLI project
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package console
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/posener/complete"
+)
+
+func TestTypedArgs(t *testing.T) {
+	called := false
+	app := &Application{
+		Writer: io.Discard,
+		Commands: []*Command{
+			{
+				Name: "wait",
+				Args: ArgDefinition{
+					{Name: "count"},
+					{Name: "ratio"},
+					{Name: "force"},
+					{Name: "timeout", Optional: true, Default: "1m30s"},
+					{Name: "hosts", Optional: true, Slice: true},
+				},
+				Action: func(c *Context) error {
+					called = true
+					if v, err := c.Args().Int("count"); err != nil || v != 3 {
+						t.Errorf("expected 3, got %v (%v)", v, err)
+					}
+					if v, err := c.Args().Float64("ratio"); err != nil || v != 0.5 {
+						t.Errorf("expected 0.5, got %v (%v)", v, err)
+					}
+					if v, err := c.Args().Bool("force"); err != nil || !v {
+						t.Errorf("expected true, got %v (%v)", v, err)
+					}
+					if v, err := c.Args().Duration("timeout"); err != nil || v != 90*time.Second {
+						t.Errorf("expected 1m30s, got %v (%v)", v, err)
+					}
+					if _, err := c.Args().Int("force"); err == nil || err.Error() != `invalid value "true" for argument "force"` {
+						t.Errorf("expected an invalid value error, got %v", err)
+					}
+					if v, err := c.Args().Int("unknown"); err != nil || v != 0 {
+						t.Errorf("expected 0 for an unknown argument, got %v (%v)", v, err)
+					}
+					if v := c.Args().Slice("hosts"); !reflect.DeepEqual(v, []string{"a", "b"}) {
+						t.Errorf("expected [a b], got %v", v)
+					}
+					if v := c.Args().Slice("count"); !reflect.DeepEqual(v, []string{"3"}) {
+						t.Errorf("expected [3], got %v", v)
+					}
+					if v := c.Args().Slice(); len(v) != 6 {
+						t.Errorf("expected all the arguments, got %v", v)
+					}
+					return nil
+				},
+			},
+		},
+	}
+
+	if _, err := app.Execute([]string{"app", "wait", "3", "0.5", "true", "1m30s", "a", "b"}); err != nil {
+		t.Fatal(err)
+	}
+	if !called {
+		t.Error("expected the action to be called")
+	}
+}
+
+func TestArgValidator(t *testing.T) {
+	app := &Application{
+		Writer: io.Discard,
+		Commands: []*Command{
+			{
+				Name: "deploy",
+				Args: ArgDefinition{
+					{Name: "project", Validator: func(c *Context, v string) error {
+						if strings.ToLower(v) != v {
+							return errors.New("must be lowercase")
+						}
+						return nil
+					}},
+					{Name: "hosts", Slice: true, Optional: true, Validator: func(c *Context, v string) error {
+						if !strings.Contains(v, ".") {
+							return errors.New("must be a domain name")
+						}
+						return nil
+					}},
+				},
+				Action: func(c *Context) error { return nil },
+			},
+		},
+	}
+
+	for args, expected := range map[string]string{
+		"website":                      "",
+		"website a.example.com":        "",
+		"Website":                      `invalid value for argument "project": must be lowercase`,
+		"website a.example.com foobar": `invalid value for argument "hosts": must be a domain name`,
+	} {
+		_, err := app.Execute(append([]string{"app", "deploy"}, strings.Fields(args)...))
+		if expected == "" {
+			if err != nil {
+				t.Errorf("%s: expected no error, got %v", args, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: expected error %q, got %v", args, expected, err)
+		}
+	}
+}
+
+func TestArgCompletion(t *testing.T) {
+	predict := func(values ...string) func(*Context, complete.Args) []string {
+		return func(*Context, complete.Args) []string {
+			return values
+		}
+	}
+	app := &Application{
+		Flags: []Flag{&StringFlag{Name: "project"}},
+		Commands: []*Command{
+			{
+				Name:  "copy",
+				Flags: []Flag{&BoolFlag{Name: "force"}, &StringFlag{Name: "mode", Aliases: []string{"m"}}},
+				Args: ArgDefinition{
+					{Name: "source", ArgsPredictor: predict("src1", "src2")},
+					{Name: "targets", Slice: true, ArgsPredictor: predict("dst1", "dst2")},
+				},
+			},
+		},
+	}
+	app.setup()
+	ctx := NewContext(app, nil, nil)
+
+	for line, expected := range map[string][]string{
+		"copy s":                          {"src1", "src2"},
+		"copy --force s":                  {"src1", "src2"},
+		"copy -m fast --project=p s":      {"src1", "src2"},
+		"copy --project p src1 d":         {"dst1", "dst2"},
+		"copy src1 dst1 d":                {"dst1", "dst2"},
+		"copy -- --weird-source d":        {"dst1", "dst2"},
+		"copy --mode=fast src1 --force d": {"dst1", "dst2"},
+	} {
+		if candidates, _ := shellCompletions(ctx, line); !reflect.DeepEqual(candidates, expected) {
+			t.Errorf("expected %v for %q, got %v", expected, line, candidates)
+		}
+	}
+}
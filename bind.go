@@ -0,0 +1,396 @@
+/*
+ * Copyright (c) 2021-present Fabien Potencier <fabien@symfony.com>
+ *
+ * This file is part of Symfony CLI project
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package console
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// binding links a field of the Command.Options struct to a flag or an
+// argument
+type binding struct {
+	index []int
+	name  string
+	flag  Flag
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// bindOptions generates the flags and arguments described by the
+// Command.Options struct tags. It panics if the struct is invalid as this can
+// only be caused by a programming error.
+func (c *Command) bindOptions() {
+	if c.Options == nil || c.bindings != nil {
+		return
+	}
+
+	v := reflect.ValueOf(c.Options)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("options of command %s must be a pointer to a struct", c.FullName()))
+	}
+
+	c.bindings = []binding{}
+	if err := c.bindStruct(v.Elem().Type(), nil); err != nil {
+		panic(fmt.Sprintf("invalid options for command %s: %s", c.FullName(), err))
+	}
+}
+
+func (c *Command) bindStruct(t reflect.Type, index []int) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldIndex := append(append([]int{}, index...), i)
+
+		tag, ok := field.Tag.Lookup("console")
+		if !ok {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				if err := c.bindStruct(field.Type, fieldIndex); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if tag == "-" {
+			continue
+		}
+		if field.PkgPath != "" {
+			return errors.Errorf(`field "%s" must be exported`, field.Name)
+		}
+
+		options, err := parseBindingTag(tag)
+		if err != nil {
+			return errors.Wrapf(err, `invalid tag for field "%s"`, field.Name)
+		}
+		name := options.name
+		if name == "" {
+			name = kebabCase(field.Name)
+		}
+
+		b := binding{index: fieldIndex, name: name}
+		switch options.kind {
+		case "flag":
+			if b.flag, err = newBindingFlag(field, name, options); err != nil {
+				return errors.Wrapf(err, `invalid flag for field "%s"`, field.Name)
+			}
+			c.Flags = append(c.Flags, b.flag)
+		case "arg":
+			arg, err := newBindingArg(field, name, options)
+			if err != nil {
+				return errors.Wrapf(err, `invalid argument for field "%s"`, field.Name)
+			}
+			c.Args = append(c.Args, arg)
+		}
+		c.bindings = append(c.bindings, b)
+	}
+
+	return nil
+}
+
+type bindingTag struct {
+	kind         string
+	name         string
+	aliases      []string
+	envVars      []string
+	defaultValue *string
+	required     bool
+	optional     bool
+	hidden       bool
+}
+
+// parseBindingTag parses tags like "flag,name=env,alias=e,env=APP_ENV,required"
+func parseBindingTag(tag string) (bindingTag, error) {
+	parts := strings.Split(tag, ",")
+	options := bindingTag{kind: strings.TrimSpace(parts[0])}
+	if options.kind != "flag" && options.kind != "arg" {
+		return options, errors.Errorf(`expected "flag" or "arg", got "%s"`, options.kind)
+	}
+
+	for _, part := range parts[1:] {
+		key, value := strings.TrimSpace(part), ""
+		if i := strings.Index(key, "="); i != -1 {
+			key, value = key[:i], key[i+1:]
+		}
+		switch key {
+		case "name":
+			options.name = value
+		case "alias":
+			options.aliases = append(options.aliases, value)
+		case "env":
+			options.envVars = append(options.envVars, value)
+		case "default":
+			value := value
+			options.defaultValue = &value
+		case "required":
+			options.required = true
+		case "optional":
+			options.optional = true
+		case "hidden":
+			options.hidden = true
+		default:
+			return options, errors.Errorf(`unknown option "%s"`, key)
+		}
+	}
+
+	return options, nil
+}
+
+func newBindingFlag(field reflect.StructField, name string, options bindingTag) (Flag, error) {
+	usage := field.Tag.Get("usage")
+	def := ""
+	if options.defaultValue != nil {
+		def = *options.defaultValue
+	}
+
+	var err error
+	switch t := field.Type; {
+	case t == durationType:
+		f := &DurationFlag{Name: name, Aliases: options.aliases, Usage: usage, EnvVars: options.envVars, Hidden: options.hidden, Required: options.required}
+		if def != "" {
+			f.DefaultValue, err = time.ParseDuration(def)
+		}
+		return f, errors.WithStack(err)
+	case t.Kind() == reflect.String:
+		return &StringFlag{Name: name, Aliases: options.aliases, Usage: usage, EnvVars: options.envVars, Hidden: options.hidden, Required: options.required, DefaultValue: def}, nil
+	case t.Kind() == reflect.Bool:
+		f := &BoolFlag{Name: name, Aliases: options.aliases, Usage: usage, EnvVars: options.envVars, Hidden: options.hidden, Required: options.required}
+		if def != "" {
+			f.DefaultValue, err = strconv.ParseBool(def)
+		}
+		return f, errors.WithStack(err)
+	case t.Kind() == reflect.Int:
+		f := &IntFlag{Name: name, Aliases: options.aliases, Usage: usage, EnvVars: options.envVars, Hidden: options.hidden, Required: options.required}
+		if def != "" {
+			f.DefaultValue, err = strconv.Atoi(def)
+		}
+		return f, errors.WithStack(err)
+	case t.Kind() == reflect.Int64:
+		f := &Int64Flag{Name: name, Aliases: options.aliases, Usage: usage, EnvVars: options.envVars, Hidden: options.hidden, Required: options.required}
+		if def != "" {
+			f.DefaultValue, err = strconv.ParseInt(def, 10, 64)
+		}
+		return f, errors.WithStack(err)
+	case t.Kind() == reflect.Uint:
+		f := &UintFlag{Name: name, Aliases: options.aliases, Usage: usage, EnvVars: options.envVars, Hidden: options.hidden, Required: options.required}
+		if def != "" {
+			var v uint64
+			v, err = strconv.ParseUint(def, 10, 0)
+			f.DefaultValue = uint(v)
+		}
+		return f, errors.WithStack(err)
+	case t.Kind() == reflect.Uint64:
+		f := &Uint64Flag{Name: name, Aliases: options.aliases, Usage: usage, EnvVars: options.envVars, Hidden: options.hidden, Required: options.required}
+		if def != "" {
+			f.DefaultValue, err = strconv.ParseUint(def, 10, 64)
+		}
+		return f, errors.WithStack(err)
+	case t.Kind() == reflect.Float64:
+		f := &Float64Flag{Name: name, Aliases: options.aliases, Usage: usage, EnvVars: options.envVars, Hidden: options.hidden, Required: options.required}
+		if def != "" {
+			f.DefaultValue, err = strconv.ParseFloat(def, 64)
+		}
+		return f, errors.WithStack(err)
+	}
+
+	if options.defaultValue != nil {
+		return nil, errors.Errorf("default values are not supported for type %s", field.Type)
+	}
+	switch t := field.Type; {
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.String:
+		return &StringSliceFlag{Name: name, Aliases: options.aliases, Usage: usage, EnvVars: options.envVars, Hidden: options.hidden, Required: options.required}, nil
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Int:
+		return &IntSliceFlag{Name: name, Aliases: options.aliases, Usage: usage, EnvVars: options.envVars, Hidden: options.hidden, Required: options.required}, nil
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Int64:
+		return &Int64SliceFlag{Name: name, Aliases: options.aliases, Usage: usage, EnvVars: options.envVars, Hidden: options.hidden, Required: options.required}, nil
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Float64:
+		return &Float64SliceFlag{Name: name, Aliases: options.aliases, Usage: usage, EnvVars: options.envVars, Hidden: options.hidden, Required: options.required}, nil
+	case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String && t.Elem().Kind() == reflect.String:
+		return &StringMapFlag{Name: name, Aliases: options.aliases, Usage: usage, EnvVars: options.envVars, Hidden: options.hidden, Required: options.required}, nil
+	}
+
+	return nil, errors.Errorf("unsupported type %s", field.Type)
+}
+
+func newBindingArg(field reflect.StructField, name string, options bindingTag) (*Arg, error) {
+	if len(options.aliases) > 0 || len(options.envVars) > 0 || options.required || options.hidden {
+		return nil, errors.New(`arguments only support the "name", "default" and "optional" options`)
+	}
+
+	arg := &Arg{
+		Name:        name,
+		Description: field.Tag.Get("usage"),
+		Optional:    options.optional || options.defaultValue != nil,
+	}
+	if field.Type.Kind() == reflect.Slice {
+		if options.defaultValue != nil {
+			return nil, errors.New("default values are not supported for slice arguments")
+		}
+		arg.Slice = true
+		if err := checkBindingArgType(field.Type.Elem()); err != nil {
+			return nil, err
+		}
+		return arg, nil
+	}
+
+	if options.defaultValue != nil {
+		arg.Default = *options.defaultValue
+	}
+	return arg, checkBindingArgType(field.Type)
+}
+
+func checkBindingArgType(t reflect.Type) error {
+	if t == durationType {
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64, reflect.Float64:
+		return nil
+	}
+	return errors.Errorf("unsupported type %s", t)
+}
+
+// fillOptions sets the fields of the Command.Options struct from the parsed
+// flags and arguments
+func (c *Command) fillOptions(ctx *Context) error {
+	if len(c.bindings) == 0 {
+		return nil
+	}
+
+	options := reflect.ValueOf(c.Options).Elem()
+	for _, b := range c.bindings {
+		field := options.FieldByIndex(b.index)
+		if b.flag != nil {
+			field.Set(reflect.ValueOf(bindingFlagValue(ctx, b.flag, b.name)).Convert(field.Type()))
+			continue
+		}
+
+		values := []string{ctx.Args().Get(b.name)}
+		if field.Kind() == reflect.Slice {
+			values = ctx.Args().Tail()
+			field.Set(reflect.MakeSlice(field.Type(), len(values), len(values)))
+			for i, value := range values {
+				if err := setFieldFromString(field.Index(i), value); err != nil {
+					return errors.Wrapf(err, `invalid value for argument "%s"`, b.name)
+				}
+			}
+			continue
+		}
+		if err := setFieldFromString(field, values[0]); err != nil {
+			return errors.Wrapf(err, `invalid value for argument "%s"`, b.name)
+		}
+	}
+
+	return nil
+}
+
+func bindingFlagValue(ctx *Context, f Flag, name string) interface{} {
+	switch f.(type) {
+	case *BoolFlag:
+		return ctx.Bool(name)
+	case *DurationFlag:
+		return ctx.Duration(name)
+	case *IntFlag:
+		return ctx.Int(name)
+	case *Int64Flag:
+		return ctx.Int64(name)
+	case *UintFlag:
+		return ctx.Uint(name)
+	case *Uint64Flag:
+		return ctx.Uint64(name)
+	case *Float64Flag:
+		return ctx.Float64(name)
+	case *StringSliceFlag:
+		return ctx.StringSlice(name)
+	case *IntSliceFlag:
+		return ctx.IntSlice(name)
+	case *Int64SliceFlag:
+		return ctx.Int64Slice(name)
+	case *Float64SliceFlag:
+		return ctx.Float64Slice(name)
+	case *StringMapFlag:
+		return ctx.StringMap(name)
+	}
+	return ctx.String(name)
+}
+
+func setFieldFromString(field reflect.Value, value string) error {
+	if value == "" {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		field.SetInt(int64(d))
+		return errors.WithStack(err)
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		field.SetBool(v)
+	case reflect.Int, reflect.Int64:
+		v, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		field.SetInt(v)
+	case reflect.Uint, reflect.Uint64:
+		v, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		field.SetUint(v)
+	case reflect.Float64:
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		field.SetFloat(v)
+	}
+
+	return nil
+}
+
+// kebabCase converts a field name like "DryRun" to "dry-run"
+func kebabCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteRune('-')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
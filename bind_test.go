@@ -0,0 +1,142 @@
+/*
+ * Copyright (c) 2021-present Fabien Potencier <fabien@symfony.com>
+ *
+ * This file is part of Symfony CLI project
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package console
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type deployOptions struct {
+	commonOptions
+	Env      string            `console:"flag,name=env,alias=e,env=APP_ENV,required" usage:"The target environment"`
+	DryRun   bool              `console:"flag"`
+	Retries  int               `console:"flag,default=3"`
+	Timeout  time.Duration     `console:"flag,default=1m"`
+	Tags     []string          `console:"flag,name=tag"`
+	Labels   map[string]string `console:"flag,name=label"`
+	Project  string            `console:"arg,name=project" usage:"The project"`
+	Replicas uint              `console:"arg,default=2"`
+	Files    []string          `console:"arg,optional"`
+	ignored  string
+}
+
+type commonOptions struct {
+	Verbose bool `console:"flag,name=debug,hidden"`
+}
+
+func TestCommandOptions(t *testing.T) {
+	t.Setenv("APP_ENV", "")
+
+	options := &deployOptions{}
+	var got deployOptions
+	app := &Application{
+		Writer: io.Discard,
+		Commands: []*Command{
+			{
+				Name:    "deploy",
+				Options: options,
+				Action: func(c *Context) error {
+					got = *options
+					return nil
+				},
+			},
+		},
+	}
+
+	_, err := app.Execute([]string{"app", "deploy", "-e", "prod", "--dry-run", "--tag=a", "--tag=b", "--label", "k=v", "--debug", "website", "5", "f1", "f2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := deployOptions{
+		commonOptions: commonOptions{Verbose: true},
+		Env:           "prod",
+		DryRun:        true,
+		Retries:       3,
+		Timeout:       time.Minute,
+		Tags:          []string{"a", "b"},
+		Labels:        map[string]string{"k": "v"},
+		Project:       "website",
+		Replicas:      5,
+		Files:         []string{"f1", "f2"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %#v, got %#v", expected, got)
+	}
+
+	cmd := app.Command("deploy")
+	if f := findFlag(cmd.Flags, "e").(*StringFlag); f.Name != "env" || !f.Required || f.Usage != "The target environment" || !reflect.DeepEqual(f.EnvVars, []string{"APP_ENV"}) {
+		t.Errorf("unexpected flag %#v", f)
+	}
+	if !findFlag(cmd.Flags, "debug").(*BoolFlag).Hidden {
+		t.Error("expected the embedded flag to be hidden")
+	}
+	if usage := cmd.Args.Usage(); usage != " [--] <project> [<replicas>] [<files>]..." {
+		t.Errorf("unexpected args usage %q", usage)
+	}
+
+	_, err = app.Execute([]string{"app", "deploy", "--env=prod", "website", "two"})
+	if err == nil || !strings.Contains(err.Error(), `invalid value for argument "replicas"`) {
+		t.Errorf("expected an invalid argument error, got %v", err)
+	}
+}
+
+func TestCommandOptions_Invalid(t *testing.T) {
+	for _, options := range []interface{}{
+		deployOptions{},
+		&struct {
+			Value complex64 `console:"flag"`
+		}{},
+		&struct {
+			Value string `console:"option"`
+		}{},
+		&struct {
+			Value []string `console:"flag,default=a"`
+		}{},
+		&struct {
+			Value string `console:"arg,alias=v"`
+		}{},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected %T to panic", options)
+				}
+			}()
+			(&Command{Name: "foo", Options: options}).bindOptions()
+		}()
+	}
+}
+
+func TestKebabCase(t *testing.T) {
+	for name, expected := range map[string]string{
+		"Env":        "env",
+		"DryRun":     "dry-run",
+		"HTTPServer": "http-server",
+		"UserID":     "user-id",
+	} {
+		if got := kebabCase(name); got != expected {
+			t.Errorf("expected %q for %q, got %q", expected, name, got)
+		}
+	}
+}
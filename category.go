@@ -91,7 +91,7 @@ func (c *commandCategory) VisibleCommands() []*Command {
 
 	ret := []*Command{}
 	for _, command := range c.commands {
-		if command.Hidden == nil || !command.Hidden() {
+		if !command.isHidden() {
 			ret = append(ret, command)
 		}
 	}
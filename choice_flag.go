@@ -0,0 +1,196 @@
+/*
+ * Copyright (c) 2021-present Fabien Potencier <fabien@symfony.com>
+ *
+ * This file is part of Symfony CLI project
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package console
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/agext/levenshtein"
+	"github.com/pkg/errors"
+	"github.com/posener/complete"
+)
+
+// ChoiceFlag is a string flag whose value must be one of Choices
+type ChoiceFlag struct {
+	Name         string
+	Aliases      []string
+	Usage        string
+	EnvVars      []string
+	Hidden       bool
+	DefaultValue string
+	DefaultText  string
+	Required     bool
+	Choices      []string
+	// CaseInsensitive accepts the choices whatever their case, the value
+	// being normalized to the matching choice
+	CaseInsensitive bool
+	ArgsPredictor   func(*Context, complete.Args) []string
+	Validator       func(*Context, string) error
+	Destination     *string
+}
+
+// String returns a readable representation of this value
+// (for usage defaults)
+func (f *ChoiceFlag) String() string {
+	return FlagStringer(f)
+}
+
+func (f *ChoiceFlag) PredictArgs(c *Context, a complete.Args) []string {
+	if f.ArgsPredictor != nil {
+		return f.ArgsPredictor(c, a)
+	}
+	return f.Choices
+}
+
+func (f *ChoiceFlag) Validate(c *Context) error {
+	fl := lookupRawFlag(f.Name, c)
+	if fl == nil {
+		return nil
+	}
+	choice, err := matchChoice(f.Choices, fl.Value.String(), f.CaseInsensitive)
+	if err != nil {
+		return err
+	}
+	if choice != fl.Value.String() {
+		if err := fl.Value.Set(choice); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if f.Validator != nil {
+		return f.Validator(c, choice)
+	}
+	return nil
+}
+
+// Apply populates the flag given the flag set and environment
+func (f *ChoiceFlag) Apply(set *flag.FlagSet) {
+	if f.Destination != nil {
+		set.StringVar(f.Destination, f.Name, f.DefaultValue, f.Usage)
+	} else {
+		set.String(f.Name, f.DefaultValue, f.Usage)
+	}
+}
+
+// Names returns the names of the flag
+func (f *ChoiceFlag) Names() []string {
+	return flagNames(f)
+}
+
+// ChoiceSliceFlag is a string slice flag whose values must be in Choices
+type ChoiceSliceFlag struct {
+	Name        string
+	Aliases     []string
+	Usage       string
+	EnvVars     []string
+	Hidden      bool
+	DefaultText string
+	Required    bool
+	Choices     []string
+	// CaseInsensitive accepts the choices whatever their case, the values
+	// being normalized to the matching choices
+	CaseInsensitive bool
+	ArgsPredictor   func(*Context, complete.Args) []string
+	Validator       func(*Context, []string) error
+	Destination     *StringSlice
+}
+
+// String returns a readable representation of this value
+// (for usage defaults)
+func (f *ChoiceSliceFlag) String() string {
+	return FlagStringer(f)
+}
+
+func (f *ChoiceSliceFlag) PredictArgs(c *Context, a complete.Args) []string {
+	if f.ArgsPredictor != nil {
+		return f.ArgsPredictor(c, a)
+	}
+	return f.Choices
+}
+
+func (f *ChoiceSliceFlag) Validate(c *Context) error {
+	fl := lookupRawFlag(f.Name, c)
+	if fl == nil {
+		return nil
+	}
+	values, ok := fl.Value.(*StringSlice)
+	if !ok {
+		return nil
+	}
+	for i, value := range values.slice {
+		choice, err := matchChoice(f.Choices, value, f.CaseInsensitive)
+		if err != nil {
+			return err
+		}
+		values.slice[i] = choice
+	}
+	if f.Validator != nil {
+		return f.Validator(c, values.Value())
+	}
+	return nil
+}
+
+// Apply populates the flag given the flag set and environment
+func (f *ChoiceSliceFlag) Apply(set *flag.FlagSet) {
+	if f.Destination == nil {
+		f.Destination = NewStringSlice()
+	}
+
+	set.Var(f.Destination, f.Name, f.Usage)
+}
+
+// Names returns the names of the flag
+func (f *ChoiceSliceFlag) Names() []string {
+	return flagNames(f)
+}
+
+// matchChoice returns the choice matching the value, or an error suggesting
+// the closest choices
+func matchChoice(choices []string, value string, caseInsensitive bool) (string, error) {
+	for _, choice := range choices {
+		if choice == value || (caseInsensitive && strings.EqualFold(choice, value)) {
+			return choice, nil
+		}
+	}
+
+	msg := fmt.Sprintf("%q is not one of the possible values (%s)", value, strings.Join(choices, ", "))
+	alternatives := []string{}
+	for _, choice := range choices {
+		candidate, v := choice, value
+		if caseInsensitive {
+			candidate, v = strings.ToLower(candidate), strings.ToLower(v)
+		}
+		if v != "" && (strings.HasPrefix(candidate, v) || levenshtein.Distance(v, candidate, nil) <= len(v)/3) {
+			alternatives = append(alternatives, fmt.Sprintf("%q", choice))
+		}
+	}
+	if len(alternatives) > 0 {
+		msg += fmt.Sprintf(", did you mean %s?", strings.Join(alternatives, " or "))
+	}
+	return "", errors.New(msg)
+}
+
+func choicesHint(choices []string) string {
+	if len(choices) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" <comment>[possible values: %s]</>", strings.Join(choices, ", "))
+}
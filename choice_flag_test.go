@@ -0,0 +1,94 @@
+/*
+ * Copyright (c) 2021-present Fabien Potencier <fabien@symfony.com>
+ *
+ * This file is part of Symfony CLI project
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package console
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/posener/complete"
+)
+
+func TestChoiceFlag(t *testing.T) {
+	var env string
+	var regions []string
+	newApp := func() *Application {
+		return &Application{
+			Writer: io.Discard,
+			Commands: []*Command{
+				{
+					Name: "deploy",
+					Flags: []Flag{
+						&ChoiceFlag{Name: "env", Choices: []string{"dev", "staging", "prod"}, DefaultValue: "dev"},
+						&ChoiceSliceFlag{Name: "region", Choices: []string{"eu-west", "us-east"}, CaseInsensitive: true},
+					},
+					Action: func(c *Context) error {
+						env = c.String("env")
+						regions = c.StringSlice("region")
+						return nil
+					},
+				},
+			},
+		}
+	}
+
+	if _, err := newApp().Execute([]string{"app", "deploy", "--env=prod", "--region=EU-West", "--region=us-east"}); err != nil {
+		t.Fatal(err)
+	}
+	if env != "prod" {
+		t.Errorf(`expected "prod", got %q`, env)
+	}
+	if !reflect.DeepEqual(regions, []string{"eu-west", "us-east"}) {
+		t.Errorf("expected the values to be normalized, got %q", regions)
+	}
+
+	for args, expected := range map[string]string{
+		"--env=prd":       `invalid value for flag "env": "prd" is not one of the possible values (dev, staging, prod), did you mean "prod"?`,
+		"--env=PROD":      `invalid value for flag "env": "PROD" is not one of the possible values (dev, staging, prod)`,
+		"--env=qa":        `"qa" is not one of the possible values (dev, staging, prod)`,
+		"--region=asia":   `invalid value for flag "region": "asia" is not one of the possible values (eu-west, us-east)`,
+		"--region=us-eas": `did you mean "us-east"?`,
+	} {
+		_, err := newApp().Execute([]string{"app", "deploy", args})
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: expected error %q, got %v", args, expected, err)
+		}
+		if strings.Contains(expected, "qa") && strings.Contains(err.Error(), "did you mean") {
+			t.Errorf("%s: expected no suggestion, got %v", args, err)
+		}
+	}
+}
+
+func TestChoiceFlagHelpAndCompletion(t *testing.T) {
+	f := &ChoiceFlag{Name: "env", Usage: "The environment", Choices: []string{"dev", "prod"}, DefaultValue: "dev"}
+	if expected := `<info>--env=value</>	The environment <comment>[possible values: dev, prod]</> <comment>[default: "dev"]</>`; f.String() != expected {
+		t.Errorf("expected %q, got %q", expected, f.String())
+	}
+	if got := f.PredictArgs(nil, complete.Args{}); !reflect.DeepEqual(got, []string{"dev", "prod"}) {
+		t.Errorf("expected the choices to be predicted, got %v", got)
+	}
+
+	sf := &ChoiceSliceFlag{Name: "region", Choices: []string{"eu", "us"}}
+	if expected := `<info>--region=value</>	<comment>[possible values: eu, us]</>`; sf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, sf.String())
+	}
+}
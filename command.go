@@ -22,6 +22,7 @@ package console
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -46,8 +47,11 @@ type Command struct {
 	Description string
 	// or a function responsible to render the description
 	DescriptionFunc DescriptionFunc
-	// The category the command is part of
+	// The category the command is part of, ignored for subcommands as they
+	// are namespaced under their parent command
 	Category string
+	// List of child commands, they inherit the flags of their parents
+	Subcommands []*Command
 	// The function to call when checking for shell command completions
 	ShellComplete ShellCompleteFunc
 	// An action to execute before any sub-subcommands are run, but after the context is ready
@@ -56,12 +60,24 @@ type Command struct {
 	// An action to execute after any subcommands are run, but after the subcommand has finished
 	// It is run even if Action() panics
 	After AfterFunc
+	// An action to execute before the arguments and flags are validated when
+	// the input is interactive, missing required ones are asked afterwards
+	Interact InteractFunc
 	// The function to call when this command is invoked
 	Action ActionFunc
+	// Middleware wraps Action, after the application one and the one of the
+	// parent commands
+	Middleware []MiddlewareFunc
 	// List of flags to parse
 	Flags []Flag
+	// Constraints on the flags, checked after they are parsed
+	FlagGroups []FlagGroup
 	// List of args to parse
 	Args ArgDefinition
+	// Options is a pointer to a struct whose fields tagged with `console:"flag"`
+	// or `console:"arg"` define flags and args, it is filled before Before
+	// and Action are called
+	Options interface{}
 	// Treat all flags as normal arguments if true
 	FlagParsing FlagParsingMode
 	// Boolean to hide this command from help
@@ -70,12 +86,37 @@ type Command struct {
 	HelpName string
 	// The name used on the CLI by the user
 	UserName string
+
+	parent      *Command
+	origin      *Command
+	loader      func(*Command) error
+	bindings    []binding
+	strictFlags bool
 }
 
 func Hide() bool {
 	return true
 }
 
+// clone returns a copy of a built-in command that can be safely mutated by
+// an application
+func (c *Command) clone() *Command {
+	cmd := *c
+	cmd.Aliases = make([]*Alias, len(c.Aliases))
+	for i, alias := range c.Aliases {
+		a := *alias
+		cmd.Aliases[i] = &a
+	}
+	cmd.Flags = append([]Flag{}, c.Flags...)
+	cmd.origin = c
+	return &cmd
+}
+
+// is reports whether the command is the given one or a copy of it
+func (c *Command) is(other *Command) bool {
+	return c != nil && (c == other || c.origin == other)
+}
+
 func (c *Command) normalizeCommandNames() {
 	c.Category = strings.ToLower(c.Category)
 	c.Name = strings.ToLower(c.Name)
@@ -88,12 +129,51 @@ func (c *Command) normalizeCommandNames() {
 // FullName returns the full name of the command.
 // For subcommands this ensures that parent commands are part of the command path
 func (c *Command) FullName() string {
-	if c.Category != "" {
-		return strings.Join([]string{c.Category, c.Name}, ":")
+	if namespace := c.namespace(); namespace != "" {
+		return strings.Join([]string{namespace, c.Name}, ":")
 	}
 	return c.Name
 }
 
+// namespace returns the full name of the parent command for subcommands or
+// the category otherwise
+func (c *Command) namespace() string {
+	if c.parent != nil {
+		return c.parent.FullName()
+	}
+	return c.Category
+}
+
+// Parent returns the parent command of a subcommand, nil otherwise
+func (c *Command) Parent() *Command {
+	return c.parent
+}
+
+// parents returns the ancestors of the command, starting from the root one
+func (c *Command) parents() []*Command {
+	parents := []*Command{}
+	for p := c.parent; p != nil; p = p.parent {
+		parents = append([]*Command{p}, parents...)
+	}
+	return parents
+}
+
+func (c *Command) isHidden() bool {
+	if c.Hidden != nil && c.Hidden() {
+		return true
+	}
+	return c.parent != nil && c.parent.isHidden()
+}
+
+// walkCommands calls fn for each command of the tree, parents before their
+// subcommands
+func walkCommands(commands []*Command, fn func(*Command)) {
+	for _, c := range commands {
+		fn(c)
+		walkCommands(c.Subcommands, fn)
+	}
+}
+
 func (c *Command) PreferredName() string {
 	name := c.FullName()
 	if name == "" && len(c.Aliases) > 0 {
@@ -110,60 +190,109 @@ func (c *Command) PreferredName() string {
 
 // Run invokes the command given the context, parses ctx.Args() to generate command-specific flags
 func (c *Command) Run(ctx *Context) (err error) {
-	if HelpFlag != nil {
+	if err := c.load(); err != nil {
+		return err
+	}
+
+	if helpFlag := ctx.App.HelpFlag; helpFlag != nil && helpFlag.Name != "" {
 		// append help to flags
-		if !hasFlag(c.Flags, HelpFlag) {
-			c.Flags = append(c.Flags, HelpFlag)
+		if !hasFlag(c.Flags, helpFlag) {
+			c.Flags = append(c.Flags, helpFlag)
 		}
 	}
 
-	set, err := c.parseArgs(ctx.rawArgs().Tail(), ctx.App.FlagEnvPrefix)
-	context := NewContext(ctx.App, set, ctx)
-	context.Command = c
+	ctx.App.sensitive.registerArgs(c.definedFlags(), c.fixArgs(ctx.rawArgs().Tail()))
+	set, sources, err := c.parseArgs(ctx.rawArgs().Tail(), ctx.App.FlagEnvPrefix)
+	// each level of the command tree gets its own context, they all share
+	// the same flag set
+	contexts := []*Context{}
+	context := ctx
+	for _, cmd := range append(c.parents(), c) {
+		context = NewContext(ctx.App, set, context)
+		context.Command = cmd
+		context.flagSources = sources
+		contexts = append(contexts, context)
+	}
 	if err == nil {
-		err = checkFlagsValidity(c.Flags, set, context)
+		err = ctx.App.applyConfig(set, c.definedFlags(), sources, append(c.parents(), c)...)
+	}
+	// the help is displayed before any interaction or validation
+	if err == nil && checkCommandHelp(context, c.FullName()) {
+		return nil
+	}
+	if err == nil {
+		err = c.interact(context)
+	}
+	ctx.App.sensitive.registerFlagValues(c.definedFlags(), set)
+	if err == nil {
+		err = checkRequiredFlags(c.definedFlags(), set)
+	}
+	if err == nil {
+		err = checkFlagsValidity(c.definedFlags(), set, context)
+	}
+	if err == nil {
+		err = checkFlagGroups(c, set, sources)
 	}
 	if err == nil {
 		err = checkRequiredArgs(c, context)
 	}
+	if err == nil {
+		err = c.fillOptions(context)
+	}
 	if err != nil {
 		_ = ShowCommandHelp(ctx, c.FullName())
 		fmt.Fprintln(ctx.App.Writer)
 		return IncorrectUsageError{err}
 	}
 
-	if checkCommandHelp(context, c.FullName()) {
-		return nil
+	if err := ctx.App.dispatchCommand(context); err != nil {
+		return err
 	}
 
-	if c.After != nil {
-		defer func() {
-			afterErr := c.After(context)
-			if afterErr != nil {
-				HandleExitCoder(err)
-				if err != nil {
-					err = newMultiError(err, afterErr)
-				} else {
-					err = afterErr
+	for _, levelCtx := range contexts {
+		cmd := levelCtx.Command
+		if cmd.After != nil {
+			defer func(cmd *Command, levelCtx *Context) {
+				afterErr := cmd.After(levelCtx)
+				if afterErr != nil {
+					if err != nil {
+						err = newMultiError(err, afterErr)
+					} else {
+						err = afterErr
+					}
 				}
+			}(cmd, levelCtx)
+		}
+
+		if cmd.Before != nil {
+			err = cmd.Before(levelCtx)
+			if err != nil {
+				_ = ShowCommandHelp(ctx, c.FullName())
+				return err
 			}
-		}()
+		}
 	}
 
-	if c.Before != nil {
-		err = c.Before(context)
-		if err != nil {
-			_ = ShowCommandHelp(ctx, c.FullName())
-			HandleExitCoder(err)
-			return err
-		}
+	if c.Action == nil && len(c.Subcommands) > 0 {
+		return ShowCommandHelp(ctx, c.FullName())
 	}
 
-	err = c.Action(context)
-	if err != nil {
-		HandleExitCoder(err)
+	return c.wrapAction(ctx.App)(context)
+}
+
+// wrapAction returns the command action wrapped by the application middleware
+// and the one of the command tree, the application one being the outermost
+func (c *Command) wrapAction(app *Application) ActionFunc {
+	middleware := append([]MiddlewareFunc{}, app.Middleware...)
+	for _, cmd := range append(c.parents(), c) {
+		middleware = append(middleware, cmd.Middleware...)
+	}
+
+	action := c.Action
+	for i := len(middleware) - 1; i >= 0; i-- {
+		action = middleware[i](action)
 	}
-	return err
+	return action
 }
 
 // Names returns the names including short names and aliases.
@@ -186,12 +315,7 @@ func (c *Command) Names() []string {
 
 // HasName returns true if Command.Name matches given name
 func (c *Command) HasName(name string, exact bool) bool {
-	possibilities := []string{}
-	if c.Category != "" {
-		possibilities = append(possibilities, strings.Join([]string{c.Category, c.Name}, ":"))
-	} else {
-		possibilities = append(possibilities, c.Name)
-	}
+	possibilities := []string{c.FullName()}
 	for _, alias := range c.Aliases {
 		possibilities = append(possibilities, alias.String())
 	}
@@ -222,7 +346,44 @@ func (c *Command) Arguments() ArgDefinition {
 	return ArgDefinition(c.Args)
 }
 
-// VisibleFlags returns a slice of the Flags with Hidden=false
+// VisibleFlags returns a slice of the Flags with Hidden=false, including the
+// ones inherited from parent commands
 func (c *Command) VisibleFlags() []Flag {
-	return visibleFlags(c.Flags)
+	return visibleFlags(c.definedFlags())
+}
+
+// VisibleSubcommands returns a slice of the Subcommands with Hidden=false
+func (c *Command) VisibleSubcommands() []*Command {
+	ret := []*Command{}
+	for _, command := range c.Subcommands {
+		if !command.isHidden() {
+			ret = append(ret, command)
+		}
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+
+	return ret
+}
+
+// inheritedFlags returns the flags defined by the parent commands which are
+// not redefined by the command itself
+func (c *Command) inheritedFlags() []Flag {
+	flags := []Flag{}
+	for p := c.parent; p != nil; p = p.parent {
+		for _, f := range p.Flags {
+			if !hasFlag(c.Flags, f) && !hasFlag(flags, f) {
+				flags = append(flags, f)
+			}
+		}
+	}
+	return flags
+}
+
+// definedFlags returns the flags of the command and the ones inherited from
+// its parents
+func (c *Command) definedFlags() []Flag {
+	return append(append([]Flag{}, c.Flags...), c.inheritedFlags()...)
 }
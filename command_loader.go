@@ -0,0 +1,144 @@
+/*
+ * Copyright (c) 2021-present Fabien Potencier <fabien@symfony.com>
+ *
+ * This file is part of Symfony CLI project
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package console
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// CommandLoader lists commands cheaply and only builds them when they are
+// dispatched or when their help is displayed.
+type CommandLoader interface {
+	// Definitions returns the description of all the commands of the loader
+	Definitions() []CommandDefinition
+	// Load builds the command with the given full name ("category:name")
+	Load(name string) (*Command, error)
+}
+
+// CommandDefinition is the lightweight description of a command used to list
+// and dispatch it before it is loaded.
+type CommandDefinition struct {
+	Name     string
+	Category string
+	Aliases  []string
+	Usage    string
+	Hidden   bool
+}
+
+// FullName returns the full name of the defined command
+func (d CommandDefinition) FullName() string {
+	if d.Category != "" {
+		return strings.Join([]string{d.Category, d.Name}, ":")
+	}
+	return d.Name
+}
+
+// FactoryCommandLoader is a CommandLoader building commands by calling a
+// factory function.
+type FactoryCommandLoader struct {
+	definitions []CommandDefinition
+	factories   map[string]func() *Command
+}
+
+// Add registers the factory building the defined command
+func (l *FactoryCommandLoader) Add(definition CommandDefinition, factory func() *Command) {
+	if l.factories == nil {
+		l.factories = make(map[string]func() *Command)
+	}
+	l.definitions = append(l.definitions, definition)
+	l.factories[definition.FullName()] = factory
+}
+
+// Definitions returns the description of all the registered commands
+func (l *FactoryCommandLoader) Definitions() []CommandDefinition {
+	return l.definitions
+}
+
+// Load builds the command with the given full name
+func (l *FactoryCommandLoader) Load(name string) (*Command, error) {
+	factory, ok := l.factories[name]
+	if !ok {
+		return nil, errors.Errorf("command %q cannot be loaded", name)
+	}
+	return factory(), nil
+}
+
+// registerLazyCommands registers a placeholder command for each definition of
+// the application command loader. Placeholders are replaced by the actual
+// commands when loaded.
+func registerLazyCommands(a *Application) {
+	if a.CommandLoader == nil {
+		return
+	}
+
+	for _, definition := range a.CommandLoader.Definitions() {
+		definition := definition
+		cmd := &Command{
+			Name:     definition.Name,
+			Category: definition.Category,
+			Usage:    definition.Usage,
+		}
+		for _, alias := range definition.Aliases {
+			cmd.Aliases = append(cmd.Aliases, &Alias{Name: alias})
+		}
+		if definition.Hidden {
+			cmd.Hidden = Hide
+		}
+		cmd.loader = func(c *Command) error {
+			loaded, err := a.CommandLoader.Load(definition.FullName())
+			if err != nil {
+				return errors.WithStack(err)
+			}
+
+			parent := c.parent
+			*c = *loaded
+			c.parent = parent
+			walkCommands([]*Command{c}, func(cmd *Command) {
+				for _, sub := range cmd.Subcommands {
+					sub.parent = cmd
+				}
+				a.setupCommand(cmd)
+				// the placeholder is already listed, not its subcommands
+				if cmd != c && a.Categories != nil {
+					a.Categories.AddCommand(cmd.namespace(), cmd)
+				}
+			})
+			if categories, ok := a.Categories.(*commandCategories); ok {
+				sort.Sort(categories)
+			}
+
+			return nil
+		}
+		a.Commands = append(a.Commands, cmd)
+	}
+}
+
+// load builds the actual command if the command is a placeholder registered
+// by a CommandLoader.
+func (c *Command) load() error {
+	if c.loader == nil {
+		return nil
+	}
+
+	return c.loader(c)
+}
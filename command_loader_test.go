@@ -0,0 +1,131 @@
+/*
+ * Copyright (c) 2021-present Fabien Potencier <fabien@symfony.com>
+ *
+ * This file is part of Symfony CLI project
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package console
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestFactoryCommandLoader(t *testing.T) {
+	loaded := []string{}
+	loader := &FactoryCommandLoader{}
+	loader.Add(CommandDefinition{Category: "cloud", Name: "deploy", Aliases: []string{"deploy"}, Usage: "Deploy the project"}, func() *Command {
+		loaded = append(loaded, "deploy")
+		return &Command{
+			Category:    "cloud",
+			Name:        "deploy",
+			Aliases:     []*Alias{{Name: "deploy"}},
+			Usage:       "Deploy the project",
+			FlagParsing: FlagParsingSkippedAfterFirstArg,
+			Flags:       []Flag{&StringFlag{Name: "env", Usage: "The environment"}},
+			Args:        ArgDefinition{{Name: "args", Slice: true, Optional: true}},
+			Action: func(c *Context) error {
+				if got := c.String("env"); got != "prod" {
+					t.Errorf(`expected "prod", got %q`, got)
+				}
+				if got := c.Args().Tail(); strings.Join(got, " ") != "php --env=dev" {
+					t.Errorf(`expected "php --env=dev", got %q`, got)
+				}
+				return nil
+			},
+		}
+	})
+	loader.Add(CommandDefinition{Category: "cloud", Name: "logs"}, func() *Command {
+		loaded = append(loaded, "logs")
+		return &Command{Category: "cloud", Name: "logs"}
+	})
+
+	buf := new(bytes.Buffer)
+	app := &Application{
+		Writer:        buf,
+		CommandLoader: loader,
+	}
+
+	if err := app.Run([]string{"app", "list"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 0 {
+		t.Errorf("expected no command to be loaded when listing, got %v", loaded)
+	}
+	if !strings.Contains(buf.String(), "<info>cloud:deploy, deploy</>") {
+		t.Errorf("expected lazy commands to be listed, got %q", buf.String())
+	}
+
+	if err := app.Run([]string{"app", "c:d", "--env=prod", "php", "--env=dev"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 1 || loaded[0] != "deploy" {
+		t.Errorf("expected only the dispatched command to be loaded, got %v", loaded)
+	}
+
+	buf.Reset()
+	if err := app.Run([]string{"app", "help", "deploy"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "--env=value") {
+		t.Errorf("expected the loaded command help to be displayed, got %q", buf.String())
+	}
+	if len(loaded) != 1 {
+		t.Errorf("expected commands to be loaded only once, got %v", loaded)
+	}
+}
+
+func TestFactoryCommandLoaderSubcommands(t *testing.T) {
+	loader := &FactoryCommandLoader{}
+	loader.Add(CommandDefinition{Category: "cloud", Name: "env"}, func() *Command {
+		return &Command{
+			Category: "cloud",
+			Name:     "env",
+			Subcommands: []*Command{
+				{Name: "get", Usage: "Get a variable", Action: func(c *Context) error { return nil }},
+				{Name: "set", Usage: "Set a variable", Action: func(c *Context) error { return nil }},
+			},
+		}
+	})
+
+	buf := new(bytes.Buffer)
+	app := &Application{
+		Writer:        buf,
+		CommandLoader: loader,
+	}
+
+	if err := app.Run([]string{"app", "list"}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "cloud:env:get") {
+		t.Errorf("expected subcommands not to be listed before loading, got %q", buf.String())
+	}
+
+	if err := app.Run([]string{"app", "cloud:env"}); err != nil {
+		t.Fatal(err)
+	}
+
+	buf.Reset()
+	if err := app.Run([]string{"app", "list"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"cloud:env:get", "cloud:env:set"} {
+		if !strings.Contains(buf.String(), name) {
+			t.Errorf("expected %s to be listed once loaded, got %q", name, buf.String())
+		}
+	}
+}
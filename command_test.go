@@ -20,8 +20,10 @@
 package console
 
 import (
+	"bytes"
 	"errors"
 	"flag"
+	"fmt"
 	"io"
 	"reflect"
 	"strings"
@@ -205,3 +207,154 @@ func TestCommandWithNoNames(t *testing.T) {
 		t.Fatalf(`expected "foo, bar", got "%v"`, name)
 	}
 }
+
+func TestSubcommands(t *testing.T) {
+	calls := []string{}
+	hook := func(name string) func(*Context) error {
+		return func(c *Context) error {
+			calls = append(calls, name)
+			return nil
+		}
+	}
+
+	set := &Command{
+		Name:   "set",
+		Flags:  []Flag{&BoolFlag{Name: "force"}},
+		Before: hook("set:before"),
+		After:  hook("set:after"),
+		Action: func(c *Context) error {
+			calls = append(calls, fmt.Sprintf("set:action(%s, %v, %s)", c.String("project"), c.Bool("force"), c.Args().Get("name")))
+			return nil
+		},
+		Args: ArgDefinition{{Name: "name"}},
+	}
+	vars := &Command{
+		Name:        "vars",
+		Subcommands: []*Command{set},
+	}
+	env := &Command{
+		Category:    "cloud",
+		Name:        "env",
+		Flags:       []Flag{&StringFlag{Name: "project", Aliases: []string{"p"}}},
+		Before:      hook("env:before"),
+		After:       hook("env:after"),
+		Subcommands: []*Command{vars},
+	}
+	app := &Application{
+		Writer:   io.Discard,
+		Commands: []*Command{env},
+	}
+
+	if err := app.Run([]string{"foo", "c:e:v:s", "-p", "acme", "--force", "FOO"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := []string{"env:before", "set:before", "set:action(acme, true, FOO)", "set:after", "env:after"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Fatalf("expected %v, got %v", expected, calls)
+	}
+
+	if name := set.FullName(); name != "cloud:env:vars:set" {
+		t.Fatalf(`expected "cloud:env:vars:set", got "%v"`, name)
+	}
+	if set.Parent() != vars || vars.Parent() != env || env.Parent() != nil {
+		t.Fatal("expected subcommands to be linked to their parent")
+	}
+	if c, _ := app.BestCommand("cloud:env:vars"); c != vars {
+		t.Fatalf("expected cloud:env:vars, got %v", c)
+	}
+	if app.Category("cloud:env:vars") == nil {
+		t.Fatal("expected subcommands to be categorized under their parent")
+	}
+}
+
+func TestSubcommandsHelp(t *testing.T) {
+	buf := new(bytes.Buffer)
+	app := &Application{
+		Writer: buf,
+		Commands: []*Command{
+			{
+				Name:  "env",
+				Flags: []Flag{&StringFlag{Name: "project", Usage: "The project"}},
+				Subcommands: []*Command{
+					{Name: "get", Usage: "Get a variable", Action: func(c *Context) error { return nil }},
+					{Name: "secret", Hidden: Hide, Action: func(c *Context) error { return nil }},
+				},
+			},
+		},
+	}
+
+	if err := app.Run([]string{"foo", "env"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	output := buf.String()
+	if !strings.Contains(output, "<comment>Available commands:</>\n  <info>env:get</>") {
+		t.Errorf("expected subcommands to be listed, got %q", output)
+	}
+	if strings.Contains(output, "env:secret") {
+		t.Errorf("expected hidden subcommands not to be listed, got %q", output)
+	}
+
+	buf.Reset()
+	if err := app.Run([]string{"foo", "help", "env:get"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if output := buf.String(); !strings.Contains(output, "--project=value") {
+		t.Errorf("expected inherited flags to be listed, got %q", output)
+	}
+}
+
+func TestCommandMiddleware(t *testing.T) {
+	calls := []string{}
+	trace := func(name string) MiddlewareFunc {
+		return func(next ActionFunc) ActionFunc {
+			return func(c *Context) error {
+				calls = append(calls, name+":before")
+				err := next(c)
+				calls = append(calls, name+":after")
+				return err
+			}
+		}
+	}
+
+	app := &Application{
+		Writer: io.Discard,
+		Commands: []*Command{
+			{
+				Name:       "parent",
+				Middleware: []MiddlewareFunc{trace("parent")},
+				Subcommands: []*Command{
+					{
+						Name:       "child",
+						Middleware: []MiddlewareFunc{trace("child")},
+						Before: func(c *Context) error {
+							calls = append(calls, "before")
+							return nil
+						},
+						Action: func(c *Context) error {
+							calls = append(calls, "action")
+							return errors.New("failure")
+						},
+					},
+				},
+			},
+		},
+	}
+	app.Use(trace("app1"), trace("app2"))
+	app.Use(func(next ActionFunc) ActionFunc {
+		return func(c *Context) error {
+			if err := next(c); err != nil {
+				return fmt.Errorf("wrapped: %w", err)
+			}
+			return nil
+		}
+	})
+
+	exitCode, err := app.Execute([]string{"app", "parent:child"})
+	if err == nil || err.Error() != "wrapped: failure" || exitCode != 1 {
+		t.Errorf("expected the error to be wrapped, got %d and %v", exitCode, err)
+	}
+	expected := []string{"before", "app1:before", "app2:before", "parent:before", "child:before", "action", "child:after", "parent:after", "app2:after", "app1:after"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected %v, got %v", expected, calls)
+	}
+}
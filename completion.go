@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"runtime/debug"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/posener/complete"
@@ -44,7 +45,7 @@ func registerAutocompleteCommands(a *Application) {
 	}
 
 	a.Commands = append(
-		[]*Command{shellAutoCompleteInstallCommand, autoCompleteCommand},
+		[]*Command{shellAutoCompleteInstallCommand.clone(), autoCompleteCommand.clone()},
 		a.Commands...,
 	)
 }
@@ -56,20 +57,42 @@ func AutocompleteAppAction(c *Context) error {
 		logger.Msgf("completion | "+format, args...)
 	}
 
+	// lazy commands are only loaded when they are part of the completed line
+	cmd := c.App.completeCommand(c, strings.Fields(os.Getenv("COMP_LINE")))
+
+	if !complete.New(c.App.HelpName, cmd).Complete() {
+		return errors.New("Could not run auto-completion")
+	}
+
+	return nil
+}
+
+// completeCommand transposes registered commands and flags to their
+// posener/complete equivalence, lazy commands are only loaded when their name
+// is one of the given words
+func (a *Application) completeCommand(c *Context, words []string) complete.Command {
+	a.loadPlugins()
 	cmd := complete.Command{
 		GlobalFlags: make(complete.Flags),
 		Sub:         make(complete.Commands),
 	}
 
-	// transpose registered commands and flags to posener/complete equivalence
-	for _, command := range c.App.Commands {
+	for _, command := range a.allCommands() {
 		// skip the completion commands itself
-		if command == shellAutoCompleteInstallCommand {
+		if command.is(shellAutoCompleteInstallCommand) {
 			continue
 		}
+		if command.loader != nil {
+			for _, word := range words {
+				if command.HasName(strings.ToLower(word), true) {
+					_ = command.load()
+					break
+				}
+			}
+		}
 		subCmd := command.convertToPosenerCompleteCommand(c)
 
-		if command.Hidden == nil || !command.Hidden() {
+		if !command.isHidden() {
 			cmd.Sub[command.FullName()] = subCmd
 		}
 		for _, alias := range command.Aliases {
@@ -79,25 +102,24 @@ func AutocompleteAppAction(c *Context) error {
 		}
 	}
 
-	for _, f := range c.App.VisibleFlags() {
+	for _, f := range a.VisibleFlags() {
 		if vf, ok := f.(*verbosityFlag); ok {
 			vf.addToPosenerFlags(c, cmd.GlobalFlags)
 			continue
 		}
 
-		predictor := ContextPredictor{f, c}
+		predictor := flagPredictor(f, c)
 
-		for _, name := range f.Names() {
+		for _, name := range append(f.Names(), negatedNames(f)...) {
 			name = fmt.Sprintf("%s%s", prefixFor(name), name)
 			cmd.GlobalFlags[name] = predictor
 		}
+		for _, name := range fileFlagNames(f) {
+			cmd.GlobalFlags["--"+name] = complete.PredictFiles("*")
+		}
 	}
 
-	if !complete.New(c.App.HelpName, cmd).Complete() {
-		return errors.New("Could not run auto-completion")
-	}
-
-	return nil
+	return cmd
 }
 
 func (c *Command) convertToPosenerCompleteCommand(ctx *Context) complete.Command {
@@ -106,9 +128,12 @@ func (c *Command) convertToPosenerCompleteCommand(ctx *Context) complete.Command
 	}
 
 	for _, f := range c.VisibleFlags() {
-		for _, name := range f.Names() {
+		for _, name := range append(f.Names(), negatedNames(f)...) {
 			name = fmt.Sprintf("%s%s", prefixFor(name), name)
-			command.Flags[name] = ContextPredictor{f, ctx}
+			command.Flags[name] = flagPredictor(f, ctx)
+		}
+		for _, name := range fileFlagNames(f) {
+			command.Flags["--"+name] = complete.PredictFiles("*")
 		}
 	}
 
@@ -119,11 +144,72 @@ func (c *Command) convertToPosenerCompleteCommand(ctx *Context) complete.Command
 	return command
 }
 
+// flagPredictor returns the predictor of the flag value, nil for boolean and
+// count flags as they do not expect any
+func flagPredictor(f Flag, ctx *Context) complete.Predictor {
+	if bf, ok := f.(*BoolFlag); ok && bf.ArgsPredictor == nil {
+		return nil
+	}
+	if cf, ok := f.(*CountFlag); ok && cf.ArgsPredictor == nil {
+		return nil
+	}
+	return ContextPredictor{f, ctx}
+}
+
 func (c *Command) PredictArgs(ctx *Context, a complete.Args) []string {
 	if c.ShellComplete != nil {
 		return c.ShellComplete(ctx, a)
 	}
 
+	if arg := c.completedArg(ctx, a.Completed); arg != nil && arg.ArgsPredictor != nil {
+		return arg.ArgsPredictor(ctx, a)
+	}
+
+	return nil
+}
+
+// completedArg returns the argument being completed, given the words typed
+// after the command name
+func (c *Command) completedArg(ctx *Context, completed []string) *Arg {
+	flags := c.definedFlags()
+	if ctx != nil && ctx.App != nil {
+		for _, f := range ctx.App.Flags {
+			if !hasFlag(flags, f) {
+				flags = append(flags, f)
+			}
+		}
+	}
+	fs := flagSet(c.Name, flags)
+
+	position := 0
+	for i := 0; i < len(completed); i++ {
+		word := completed[i]
+		if word == "--" {
+			position += len(completed) - i - 1
+			break
+		}
+		if len(word) < 2 || word[0] != '-' {
+			position++
+			continue
+		}
+		if strings.Contains(word, "=") {
+			continue
+		}
+		f := fs.Lookup(expandShortcut(flags, strings.TrimLeft(word, "-")))
+		if f == nil {
+			continue
+		}
+		if bf, ok := f.Value.(interface{ IsBoolFlag() bool }); !ok || !bf.IsBoolFlag() {
+			// the next word is the flag value
+			i++
+		}
+	}
+
+	for i, arg := range c.Args {
+		if i == position || (arg.Slice && i < position) {
+			return arg
+		}
+	}
 	return nil
 }
 
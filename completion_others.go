@@ -2,6 +2,8 @@
 
 package console
 
+import "github.com/posener/complete"
+
 const HasAutocompleteSupport = false
 
 func IsAutocomplete(c *Command) bool {
@@ -10,3 +12,9 @@ func IsAutocomplete(c *Command) bool {
 
 func registerAutocompleteCommands(a *Application) {
 }
+
+// completeCommand returns an empty command as completion is not supported on
+// this platform
+func (a *Application) completeCommand(c *Context, words []string) complete.Command {
+	return complete.Command{}
+}
@@ -6,5 +6,5 @@ package console
 const SupportsAutocomplete = true
 
 func IsAutocomplete(c *Command) bool {
-	return c == autoCompleteCommand
+	return c.is(autoCompleteCommand)
 }
@@ -0,0 +1,332 @@
+/*
+ * Copyright (c) 2021-present Fabien Potencier <fabien@symfony.com>
+ *
+ * This file is part of Symfony CLI project
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package console
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+	"github.com/symfony-cli/terminal"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigSource provides flag values, typically read from a configuration file.
+//
+// Top-level keys are matched against the application flags and the command
+// ones, while a key named after a command (like "cloud:deploy") holds values
+// only applying to this command and its subcommands. Sources implementing
+// fmt.Stringer are reported as the file of the values in FlagSource.
+type ConfigSource interface {
+	Load() (map[string]interface{}, error)
+}
+
+// ConfigSourceFunc is an adapter to use a function as a ConfigSource
+type ConfigSourceFunc func() (map[string]interface{}, error)
+
+// Load calls f()
+func (f ConfigSourceFunc) Load() (map[string]interface{}, error) {
+	return f()
+}
+
+// ConfigFile returns a ConfigSource reading the given file, its format is
+// guessed from its extension (.json, .yaml, .yml, .ini or .toml). Missing
+// files are ignored.
+func ConfigFile(path string) ConfigSource {
+	return &configFile{path: path}
+}
+
+type configFile struct {
+	path string
+}
+
+func (f *configFile) String() string {
+	return ExpandHome(f.path)
+}
+
+func (f *configFile) Load() (map[string]interface{}, error) {
+	path := f.String()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	values := map[string]interface{}{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		err = json.Unmarshal(data, &values)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	case ".ini":
+		values, err = parseINI(data)
+	default:
+		return nil, errors.Errorf("Unsupported configuration file format %q for %s", ext, path)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "Unable to parse configuration file %s", path)
+	}
+	return values, nil
+}
+
+// loadedConfig holds the values of a configuration source, file being set
+// for sources implementing fmt.Stringer
+type loadedConfig struct {
+	file   string
+	values map[string]interface{}
+}
+
+func (a *Application) loadConfig() error {
+	a.config = nil
+	for _, source := range a.ConfigSources {
+		values, err := source.Load()
+		if err != nil {
+			return err
+		}
+		if len(values) == 0 {
+			continue
+		}
+		config := loadedConfig{values: values}
+		if s, ok := source.(fmt.Stringer); ok {
+			config.file = s.String()
+		}
+		a.config = append(a.config, config)
+	}
+	return nil
+}
+
+// applyConfig sets the flags not already set on the command line or via the
+// environment from the configuration. The sections named after the given
+// commands override the top-level values, and later sources take precedence
+// over the previous ones.
+func (a *Application) applyConfig(fs *flag.FlagSet, flags []Flag, sources flagSources, commands ...*Command) error {
+	if len(a.config) == 0 {
+		return nil
+	}
+
+	// most specific scope first, the empty one being the top-level
+	scopes := []string{}
+	for i := len(commands) - 1; i >= 0; i-- {
+		scopes = append(scopes, commands[i].FullName())
+	}
+	scopes = append(scopes, "")
+
+	definedFlags := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		definedFlags[f.Name] = true
+	})
+
+	for _, f := range flags {
+		fName := flagName(f)
+		if definedFlags[fName] {
+			continue
+		}
+		value, source, ok := a.configValue(scopes, f)
+		if !ok {
+			continue
+		}
+
+		fl := fs.Lookup(fName)
+		if fl == nil {
+			continue
+		}
+		val, err := configFlagValue(fl.Value, value)
+		if flagIsSensitive(f) {
+			a.sensitive.register(val)
+		}
+		if err == nil {
+			err = fs.Set(fName, val)
+		}
+		if err != nil {
+			return errors.Errorf("Invalid value for the %s: %s", source, err)
+		}
+		terminal.Logger.Trace().Msgf("Using %s for '%s' configuration entry.\n", source, fName)
+		expandHomeInFlagsValues(fl)
+		if sources != nil {
+			sources[fName] = source
+		}
+	}
+	return nil
+}
+
+// configValue looks for the flag in the configuration
+func (a *Application) configValue(scopes []string, f Flag) (interface{}, FlagSource, bool) {
+	for _, scope := range scopes {
+		for i := len(a.config) - 1; i >= 0; i-- {
+			values := a.config[i].values
+			if scope != "" {
+				section, ok := values[scope].(map[string]interface{})
+				if !ok {
+					continue
+				}
+				values = section
+			}
+			if value, key, ok := configEntry(values, f); ok {
+				if scope != "" {
+					key = scope + "." + key
+				}
+				return value, FlagSource{Kind: FlagSourceConfig, Name: key, File: a.config[i].file}, true
+			}
+		}
+	}
+	return nil, FlagSource{}, false
+}
+
+// configEntry looks for the flag in the configuration values, using any of
+// its names, dashes being optionally replaced by underscores
+func configEntry(values map[string]interface{}, f Flag) (interface{}, string, bool) {
+	for _, name := range f.Names() {
+		for _, key := range []string{name, strings.ReplaceAll(name, "-", "_")} {
+			if value, ok := values[key]; ok {
+				return value, key, true
+			}
+		}
+	}
+	return nil, "", false
+}
+
+// configFlagValue converts a configuration value to a string suitable for
+// flag.Value.Set, slices and maps being passed in their serialized form
+func configFlagValue(value flag.Value, v interface{}) (string, error) {
+	if _, ok := value.(Serializeder); !ok {
+		switch v.(type) {
+		case []interface{}, map[string]interface{}:
+			return "", errors.New("a single value is expected")
+		}
+		return configString(v), nil
+	}
+
+	getter := reflect.ValueOf(value).MethodByName("Value")
+	if !getter.IsValid() {
+		return "", errors.Errorf("unsupported flag value %T", value)
+	}
+	typ := getter.Type().Out(0)
+	switch t := v.(type) {
+	case []interface{}:
+		if typ.Kind() != reflect.Slice {
+			return "", errors.New("a map is expected")
+		}
+		if typ.Elem().Kind() == reflect.String {
+			strs := make([]string, len(t))
+			for i, e := range t {
+				strs[i] = configString(e)
+			}
+			v = strs
+		}
+	case map[string]interface{}:
+		if typ.Kind() != reflect.Map {
+			return "", errors.New("a list is expected")
+		}
+		m := make(map[string]string, len(t))
+		for k, e := range t {
+			m[k] = configString(e)
+		}
+		v = m
+	default:
+		if typ.Kind() != reflect.Slice {
+			return "", errors.New("a map is expected")
+		}
+		if typ.Elem().Kind() == reflect.String {
+			v = configString(v)
+		}
+		v = []interface{}{v}
+	}
+
+	jsonBytes, err := json.Marshal(v)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	// Set ignores deserialization errors, so make sure the value is valid
+	if err := json.Unmarshal(jsonBytes, reflect.New(typ).Interface()); err != nil {
+		return "", errors.Errorf("invalid value %s", jsonBytes)
+	}
+	return fmt.Sprintf("%s%s", slPfx, string(jsonBytes)), nil
+}
+
+func configString(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	default:
+		return fmt.Sprint(t)
+	}
+}
+
+// parseINI parses INI documents made of sections, key = value pairs and
+// comments (lines starting with # or ;). Values are strings, surrounding
+// quotes being removed: use TOML for typed values and lists.
+func parseINI(data []byte) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	current := values
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		if line[0] == '[' {
+			name := strings.TrimSpace(strings.TrimPrefix(line, "["))
+			if !strings.HasSuffix(name, "]") {
+				return nil, errors.Errorf("line %d: missing closing bracket", i+1)
+			}
+			name = strings.TrimSpace(strings.TrimSuffix(name, "]"))
+			if name == "" {
+				return nil, errors.Errorf("line %d: missing section name", i+1)
+			}
+			section, ok := values[name].(map[string]interface{})
+			if !ok {
+				section = map[string]interface{}{}
+				values[name] = section
+			}
+			current = section
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, errors.Errorf("line %d: expected key = value", i+1)
+		}
+		current[key] = unquoteINI(strings.TrimSpace(value))
+	}
+	return values, nil
+}
+
+func unquoteINI(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
@@ -0,0 +1,168 @@
+/*
+ * Copyright (c) 2021-present Fabien Potencier <fabien@symfony.com>
+ *
+ * This file is part of Symfony CLI project
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package console
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConfigSources(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	jsonFile := write("config.json", `{
+	"api-url": "https://example.com",
+	"env": "staging",
+	"deploy": {"tag": ["a", "b"], "retries": 2}
+}`)
+	yamlFile := write("config.yaml", `
+env: prod
+deploy:
+  label:
+    team: core
+  retries: 5
+  dry_run: true
+`)
+	tomlFile := write("config.toml", `
+# overrides the YAML file
+[deploy]
+retries = 3
+weights = [
+  1.5,
+  2,
+]
+`)
+
+	var got []interface{}
+	var retriesSource FlagSource
+	newApp := func() *Application {
+		return &Application{
+			Writer: io.Discard,
+			Flags:  []Flag{&StringFlag{Name: "api-url"}},
+			ConfigSources: []ConfigSource{
+				ConfigFile(jsonFile),
+				ConfigFile(yamlFile),
+				ConfigFile(tomlFile),
+				ConfigFile(filepath.Join(dir, "missing.json")),
+			},
+			Commands: []*Command{
+				{
+					Name: "deploy",
+					Flags: []Flag{
+						&StringFlag{Name: "env", DefaultValue: "dev", EnvVars: []string{"DEPLOY_ENV"}},
+						&BoolFlag{Name: "dry-run"},
+						&IntFlag{Name: "retries"},
+						&StringSliceFlag{Name: "tag"},
+						&StringMapFlag{Name: "label"},
+						&Float64SliceFlag{Name: "weights"},
+					},
+					Action: func(c *Context) error {
+						retriesSource, _ = c.FlagSource("retries")
+						got = []interface{}{c.String("api-url"), c.String("env"), c.Bool("dry-run"), c.Int("retries"), c.StringSlice("tag"), c.StringMap("label"), c.Float64Slice("weights")}
+						return nil
+					},
+				},
+			},
+		}
+	}
+
+	if _, err := newApp().Execute([]string{"app", "deploy"}); err != nil {
+		t.Fatal(err)
+	}
+	expected := []interface{}{"https://example.com", "prod", true, 3, []string{"a", "b"}, map[string]string{"team": "core"}, []float64{1.5, 2}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if expected := (FlagSource{Kind: FlagSourceConfig, Name: "deploy.retries", File: tomlFile}); retriesSource != expected {
+		t.Errorf("expected %v, got %v", expected, retriesSource)
+	}
+
+	t.Setenv("DEPLOY_ENV", "from-env")
+	if _, err := newApp().Execute([]string{"app", "deploy", "--tag=c"}); err != nil {
+		t.Fatal(err)
+	}
+	if got[1] != "from-env" || !reflect.DeepEqual(got[4], []string{"c"}) {
+		t.Errorf("expected the environment and the CLI to take precedence, got %v", got)
+	}
+
+	app := newApp()
+	app.ConfigSources = append(app.ConfigSources, ConfigSourceFunc(func() (map[string]interface{}, error) {
+		return map[string]interface{}{"deploy": map[string]interface{}{"retries": "many"}}, nil
+	}))
+	_, err := app.Execute([]string{"app", "deploy"})
+	if err == nil || !strings.Contains(err.Error(), `Invalid value for the configuration key "deploy.retries"`) {
+		t.Errorf("expected an invalid value error, got %v", err)
+	}
+
+	app = newApp()
+	app.ConfigSources = []ConfigSource{ConfigFile(write("config.txt", ""))}
+	_, err = app.Execute([]string{"app", "deploy"})
+	if err == nil || !strings.Contains(err.Error(), "Unsupported configuration file format") {
+		t.Errorf("expected an unsupported format error, got %v", err)
+	}
+}
+
+func TestParseINI(t *testing.T) {
+	values, err := parseINI([]byte(`
+; global values
+# another comment
+name = "a quoted value"
+path = /usr/local/bin
+literal = 'C:\dir'
+url = https://example.com/#anchor
+debug = true
+
+[cloud:deploy]
+count = 3
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"name":    "a quoted value",
+		"path":    "/usr/local/bin",
+		"literal": `C:\dir`,
+		"url":     "https://example.com/#anchor",
+		"debug":   "true",
+		"cloud:deploy": map[string]interface{}{
+			"count": "3",
+		},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %#v, got %#v", expected, values)
+	}
+
+	for _, invalid := range []string{"[section", "[]", "key", "= value"} {
+		if _, err := parseINI([]byte(invalid)); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+}
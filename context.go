@@ -20,8 +20,10 @@
 package console
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"sync"
 
 	"github.com/pkg/errors"
 )
@@ -34,9 +36,16 @@ type Context struct {
 	App     *Application
 	Command *Command
 
+	ctx           context.Context
+	stopSignals   func()
 	flagSet       *flag.FlagSet
+	flagSources   flagSources
 	args          *args
 	parentContext *Context
+
+	// commandMu protects Command while the application resolves it, as
+	// signal handlers read it concurrently
+	commandMu sync.Mutex
 }
 
 // NewContext creates a new context. For use in when invoking an App or Command action.
@@ -44,15 +53,82 @@ func NewContext(app *Application, set *flag.FlagSet, parentCtx *Context) *Contex
 	return &Context{App: app, flagSet: set, parentContext: parentCtx}
 }
 
+// Context returns the context.Context of the current execution. It is
+// canceled when the application is interrupted so long-running actions can
+// stop gracefully.
+func (c *Context) Context() context.Context {
+	for cur := c; cur != nil; cur = cur.parentContext {
+		if cur.ctx != nil {
+			return cur.ctx
+		}
+	}
+
+	return context.Background()
+}
+
+func (c *Context) setCommand(command *Command) {
+	c.commandMu.Lock()
+	defer c.commandMu.Unlock()
+	c.Command = command
+}
+
+func (c *Context) command() *Command {
+	c.commandMu.Lock()
+	defer c.commandMu.Unlock()
+	return c.Command
+}
+
 // Set assigns a value to a context flag.
 func (c *Context) Set(name, value string) error {
-	if fs := lookupFlagSet(name, c); fs != nil {
-		return errors.WithStack(fs.Set(name, value))
+	if ctx, name := lookupFlagContext(name, c); ctx != nil {
+		if err := ctx.flagSet.Set(name, value); err != nil {
+			return errors.WithStack(err)
+		}
+		ctx.setFlagSource(name, FlagSourceProgrammatic)
+		return nil
 	}
 
 	return fmt.Errorf("no such flag -%v", name)
 }
 
+// SetArg assigns a value to a command argument, all the values of a slice
+// argument are replaced.
+func (c *Context) SetArg(name string, values ...string) error {
+	if c.Command == nil {
+		return errors.Errorf(`no such argument "%s"`, name)
+	}
+
+	current := c.Args().Slice()
+	for i, arg := range c.Command.Args {
+		if arg.Name != name {
+			continue
+		}
+		if !arg.Slice && len(values) != 1 {
+			return errors.Errorf(`argument "%s" expects a single value`, name)
+		}
+
+		for len(current) < i {
+			current = append(current, c.Command.Args[len(current)].Default)
+		}
+		if arg.Slice {
+			current = append(current[:i], values...)
+		} else if len(current) == i {
+			current = append(current, values[0])
+		} else {
+			current[i] = values[0]
+		}
+
+		// "--" makes sure values are not parsed as flags
+		if err := c.flagSet.Parse(append([]string{"--"}, current...)); err != nil {
+			return errors.WithStack(err)
+		}
+		c.args = nil
+		return nil
+	}
+
+	return errors.Errorf(`no such argument "%s"`, name)
+}
+
 // IsSet determines if the flag was actually set
 func (c *Context) IsSet(name string) bool {
 	if fs := lookupFlagSet(name, c); fs != nil {
@@ -70,6 +146,35 @@ func (c *Context) IsSet(name string) bool {
 	return false
 }
 
+// FlagSource reports where the value of a flag comes from: the command line,
+// an environment variable, a configuration source, an interactive prompt, a
+// call to Set, or its default value. It returns false if the flag is not
+// defined.
+func (c *Context) FlagSource(name string) (FlagSource, bool) {
+	ctx, name := lookupFlagContext(name, c)
+	if ctx == nil {
+		return FlagSource{}, false
+	}
+	if source, ok := ctx.flagSources[name]; ok {
+		return source, true
+	}
+
+	source := FlagSource{Kind: FlagSourceDefault}
+	ctx.flagSet.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			source.Kind = FlagSourceCommandLine
+		}
+	})
+	return source, true
+}
+
+func (c *Context) setFlagSource(name string, kind FlagSourceKind) {
+	if c.flagSources == nil {
+		c.flagSources = flagSources{}
+	}
+	c.flagSources[name] = FlagSource{Kind: kind}
+}
+
 // HasFlag determines if a flag is defined in this context and all of its parent
 // contexts.
 func (c *Context) HasFlag(name string) bool {
@@ -167,6 +272,24 @@ func lookupFlagSet(name string, ctx *Context) *flag.FlagSet {
 	return nil
 }
 
+// lookupFlagContext returns the context owning the flag set defining the
+// flag, along with the flag name once shortcuts are expanded
+func lookupFlagContext(name string, ctx *Context) (*Context, string) {
+	for _, c := range ctx.Lineage() {
+		if c.Command != nil {
+			name = expandShortcut(c.Command.Flags, name)
+		}
+		if c.App != nil {
+			name = expandShortcut(c.App.Flags, name)
+		}
+		if f := c.flagSet.Lookup(name); f != nil {
+			return c, f.Name
+		}
+	}
+
+	return nil, name
+}
+
 func lookupRawFlag(name string, ctx *Context) *flag.Flag {
 	for _, c := range ctx.Lineage() {
 		if c.Command != nil {
@@ -21,6 +21,8 @@ package console
 
 import (
 	"flag"
+	"io"
+	"os"
 	"time"
 
 	"github.com/symfony-cli/terminal"
@@ -280,3 +282,51 @@ func (cs *ContextSuite) TestContext_lookupFlagSet(c *C) {
 		c.Fail()
 	}
 }
+
+func (cs *ContextSuite) TestContext_FlagSource(c *C) {
+	c.Assert(os.Setenv("APP_TOKEN", "secret"), IsNil)
+	defer os.Unsetenv("APP_TOKEN")
+
+	var sources map[string]string
+	app := &Application{
+		Writer:        io.Discard,
+		Flags:         []Flag{&StringFlag{Name: "token", EnvVars: []string{"APP_TOKEN"}}},
+		FlagEnvPrefix: []string{"APP"},
+		ConfigSources: []ConfigSource{ConfigSourceFunc(func() (map[string]interface{}, error) {
+			return map[string]interface{}{"deploy": map[string]interface{}{"region": "eu"}}, nil
+		})},
+		Commands: []*Command{
+			{
+				Name: "deploy",
+				Flags: []Flag{
+					&StringFlag{Name: "env", Aliases: []string{"e"}},
+					&StringFlag{Name: "region"},
+					&StringFlag{Name: "tag"},
+					&StringFlag{Name: "user"},
+				},
+				Action: func(ctx *Context) error {
+					c.Assert(ctx.Set("user", "fabien"), IsNil)
+					sources = map[string]string{}
+					for _, name := range []string{"token", "e", "region", "tag", "user"} {
+						source, ok := ctx.FlagSource(name)
+						c.Assert(ok, Equals, true)
+						sources[name] = source.String()
+					}
+					_, ok := ctx.FlagSource("unknown")
+					c.Assert(ok, Equals, false)
+					return nil
+				},
+			},
+		},
+	}
+
+	_, err := app.Execute([]string{"app", "deploy", "-e", "prod"})
+	c.Assert(err, IsNil)
+	c.Assert(sources, DeepEquals, map[string]string{
+		"token":  "environment variable APP_TOKEN",
+		"e":      "command line",
+		"region": `configuration key "deploy.region"`,
+		"tag":    "default",
+		"user":   "program",
+	})
+}
@@ -0,0 +1,150 @@
+/*
+ * Copyright (c) 2021-present Fabien Potencier <fabien@symfony.com>
+ *
+ * This file is part of Symfony CLI project
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package console
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/posener/complete"
+)
+
+// CountFlag is a flag counting its occurrences: "-d -d", "-dd" and "-d=2"
+// all set it to 2
+type CountFlag struct {
+	Name         string
+	Aliases      []string
+	Usage        string
+	EnvVars      []string
+	Hidden       bool
+	DefaultValue int
+	DefaultText  string
+	Required     bool
+	// Max is the maximum number of occurrences, 0 meaning no limit
+	Max           int
+	ArgsPredictor func(*Context, complete.Args) []string
+	Validator     func(*Context, int) error
+	Destination   *int
+}
+
+// String returns a readable representation of this value
+// (for usage defaults)
+func (f *CountFlag) String() string {
+	return FlagStringer(f)
+}
+
+func stringifyCountFlag(f *CountFlag) string {
+	_, usage := unquoteUsage(f.Usage)
+	hint := " <comment>(repeatable)</>"
+	if f.Max > 0 {
+		hint = fmt.Sprintf(" <comment>(repeatable up to %d times)</>", f.Max)
+	}
+	if f.DefaultText != "" {
+		hint += fmt.Sprintf(" <comment>[default: %s]</>", f.DefaultText)
+	} else if f.DefaultValue != 0 {
+		hint += fmt.Sprintf(" <comment>[default: %d]</>", f.DefaultValue)
+	}
+	if f.Required {
+		hint += " <comment>(required)</>"
+	}
+
+	return fmt.Sprintf("<info>%s</>\t%s", prefixedNames(f.Names(), ""), strings.TrimSpace(usage+hint))
+}
+
+func (f *CountFlag) PredictArgs(c *Context, a complete.Args) []string {
+	if f.ArgsPredictor != nil {
+		return f.ArgsPredictor(c, a)
+	}
+	return []string{}
+}
+
+func (f *CountFlag) Validate(c *Context) error {
+	count := c.Count(f.Name)
+	if f.Max > 0 && count > f.Max {
+		return errors.Errorf("cannot be used more than %d times", f.Max)
+	}
+	if f.Validator != nil {
+		return f.Validator(c, count)
+	}
+	return nil
+}
+
+// Apply populates the flag given the flag set and environment
+func (f *CountFlag) Apply(set *flag.FlagSet) {
+	n := f.Destination
+	if n == nil {
+		n = new(int)
+	}
+	*n = f.DefaultValue
+	set.Var(&countValue{n}, f.Name, f.Usage)
+}
+
+// Names returns the names of the flag
+func (f *CountFlag) Names() []string {
+	return flagNames(f)
+}
+
+// Count looks up the value of a local CountFlag, returns 0 if not found
+func (c *Context) Count(name string) int {
+	if f := lookupRawFlag(name, c); f != nil {
+		if v, ok := f.Value.(*countValue); ok {
+			return *v.n
+		}
+	}
+	return 0
+}
+
+// countValue is a boolean-like flag.Value, each occurrence without a value
+// incrementing it
+type countValue struct {
+	n *int
+}
+
+func (v *countValue) IsBoolFlag() bool { return true }
+
+func (v *countValue) Set(s string) error {
+	switch s {
+	case "true":
+		*v.n++
+	case "false":
+		*v.n = 0
+	default:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		*v.n = n
+	}
+	return nil
+}
+
+func (v *countValue) Get() interface{} {
+	return *v.n
+}
+
+func (v *countValue) String() string {
+	if v.n == nil {
+		return "0"
+	}
+	return strconv.Itoa(*v.n)
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright (c) 2021-present Fabien Potencier <fabien@symfony.com>
+ *
+ * This file is part of Symfony CLI project
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package console
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCountFlag(t *testing.T) {
+	var debug, retries int
+	app := &Application{
+		Writer: io.Discard,
+		Flags:  []Flag{&CountFlag{Name: "debug", Aliases: []string{"d"}, Max: 3}},
+		Commands: []*Command{
+			{
+				Name:  "fetch",
+				Flags: []Flag{&CountFlag{Name: "retry", Aliases: []string{"r"}, DefaultValue: 1}},
+				Args:  ArgDefinition{{Name: "url", Optional: true}},
+				Action: func(c *Context) error {
+					debug, retries = c.Count("debug"), c.Count("retry")
+					return nil
+				},
+			},
+		},
+	}
+
+	for args, expected := range map[string][2]int{
+		"fetch":                  {0, 1},
+		"-d fetch":               {1, 1},
+		"-d -d fetch -r":         {2, 2},
+		"-dd fetch -rrrr url":    {2, 5},
+		"fetch --debug -d -r -r": {2, 3},
+		"fetch --debug=3 url":    {3, 1},
+		"fetch -r=0":             {0, 0},
+	} {
+		if _, err := app.Execute(append([]string{"app"}, strings.Fields(args)...)); err != nil {
+			t.Fatalf("%s: %v", args, err)
+		}
+		if got := [2]int{debug, retries}; got != expected {
+			t.Errorf("%s: expected %v, got %v", args, expected, got)
+		}
+	}
+
+	_, err := app.Execute([]string{"app", "-dddd", "fetch"})
+	if err == nil || !strings.Contains(err.Error(), `invalid value for flag "debug": cannot be used more than 3 times`) {
+		t.Errorf("expected a max error, got %v", err)
+	}
+
+	f := &CountFlag{Name: "debug", Aliases: []string{"d"}, Usage: "Increase the debug level", Max: 3}
+	if expected := "<info>--debug, -d</>\tIncrease the debug level <comment>(repeatable up to 3 times)</>"; f.String() != expected {
+		t.Errorf("expected %q, got %q", expected, f.String())
+	}
+}
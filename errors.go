@@ -27,6 +27,7 @@ import (
 	"reflect"
 	"strings"
 
+	"github.com/pkg/errors"
 	"github.com/symfony-cli/terminal"
 )
 
@@ -58,6 +59,16 @@ func (m *multiError) Error() string {
 	return strings.Join(errs, "\n")
 }
 
+// Is reports whether any of the errors matches target
+func (m *multiError) Is(target error) bool {
+	for _, err := range *m {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
 // Errors returns a copy of the errors slice
 func (m *multiError) Errors() []error {
 	errs := make([]error, len(*m))
@@ -110,13 +121,18 @@ func HandleExitCoder(err error) {
 }
 
 func HandleError(err error) {
+	handleError(err, nil)
+}
+
+// handleError displays err, the sensitive values are masked
+func handleError(err error, sensitive *sensitiveValues) {
 	if err == nil {
 		return
 	}
 
 	if multiErr, ok := err.(MultiError); ok {
 		for _, merr := range multiErr.Errors() {
-			HandleError(merr)
+			handleError(merr, sensitive)
 		}
 		return
 	}
@@ -127,12 +143,13 @@ func HandleError(err error) {
 		if terminal.IsVerbose() && isGoRun() {
 			msg = fmt.Sprintf("[%s]\n%s", reflect.TypeOf(err), err)
 		}
+		msg = sensitive.redact(msg)
 
 		buf.WriteString(terminal.FormatBlockMessage("error", msg))
 
 		if terminal.IsVerbose() {
 			var traceBuf bytes.Buffer
-			if FormatErrorChain(&traceBuf, err, !isGoRun()) {
+			if formatErrorChain(&traceBuf, err, !isGoRun(), sensitive) {
 				buf.WriteString("\n<comment>Error trace:</>\n")
 				buf.Write(traceBuf.Bytes())
 			}
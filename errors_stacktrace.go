@@ -104,6 +104,10 @@ type stackTracer interface {
 func pc(f errors.Frame) uintptr { return uintptr(f) - 1 }
 
 func FormatErrorChain(buf *bytes.Buffer, err error, trimPaths bool) bool {
+	return formatErrorChain(buf, err, trimPaths, nil)
+}
+
+func formatErrorChain(buf *bytes.Buffer, err error, trimPaths bool, sensitive *sensitiveValues) bool {
 	var parent error
 
 	// Go up in the error tree following causes.
@@ -144,7 +148,7 @@ func FormatErrorChain(buf *bytes.Buffer, err error, trimPaths bool) bool {
 		msg = strings.TrimSuffix(msg, fmt.Sprintf(": %s", parent.Error()))
 	}
 
-	buf.WriteString(terminal.FormatBlockMessage("error", msg))
+	buf.WriteString(terminal.FormatBlockMessage("error", sensitive.redact(msg)))
 
 	for _, f := range st {
 		buf.WriteString("\n")
@@ -165,7 +169,7 @@ func FormatErrorChain(buf *bytes.Buffer, err error, trimPaths bool) bool {
 	if parent != nil {
 		buf.WriteByte('\n')
 		buf.WriteString("Previous error:\n")
-		FormatErrorChain(buf, parent, trimPaths)
+		formatErrorChain(buf, parent, trimPaths, sensitive)
 	}
 
 	return true
@@ -0,0 +1,240 @@
+/*
+ * Copyright (c) 2021-present Fabien Potencier <fabien@symfony.com>
+ *
+ * This file is part of Symfony CLI project
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package console
+
+import (
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Names of the events dispatched during the lifecycle of an application
+const (
+	// CommandEvent is dispatched before a command is executed, listeners can
+	// disable the command
+	CommandEvent = "console.command"
+	// ErrorEvent is dispatched when the execution returns an error, listeners
+	// can replace the error or change the exit code
+	ErrorEvent = "console.error"
+	// TerminateEvent is dispatched once the execution is over, listeners can
+	// change the exit code
+	TerminateEvent = "console.terminate"
+	// SignalEvent is dispatched when the application receives SIGINT or
+	// SIGTERM, listeners are called from a separate goroutine
+	SignalEvent = "console.signal"
+)
+
+// CommandDisabledExitCode is the exit code used when a listener disables the
+// command
+const CommandDisabledExitCode = 113
+
+var errCommandDisabled = Exit("", CommandDisabledExitCode)
+
+// Event is passed to the listeners of the lifecycle events
+type Event struct {
+	// Name of the dispatched event
+	Name string
+	// Context of the execution
+	Context *Context
+	// Command being executed, nil if no command matched
+	Command *Command
+	// Signal received, only set for SignalEvent
+	Signal os.Signal
+
+	err                error
+	exitCode           int
+	commandDisabled    bool
+	propagationStopped bool
+}
+
+func newEvent(name string, ctx *Context) *Event {
+	e := &Event{Name: name, Context: ctx}
+	if ctx != nil {
+		e.Command = ctx.command()
+	}
+	return e
+}
+
+// Err returns the error of the execution
+func (e *Event) Err() error {
+	return e.err
+}
+
+// SetErr replaces the error of the execution, the exit code is updated
+// accordingly
+func (e *Event) SetErr(err error) {
+	e.err = err
+	e.exitCode = 0
+	if err != nil {
+		e.exitCode = handleExitCode(err)
+	}
+}
+
+// ExitCode returns the exit code of the execution
+func (e *Event) ExitCode() int {
+	return e.exitCode
+}
+
+// SetExitCode changes the exit code of the execution
+func (e *Event) SetExitCode(code int) {
+	e.exitCode = code
+}
+
+// DisableCommand prevents the command from being executed, only relevant for
+// CommandEvent
+func (e *Event) DisableCommand() {
+	e.commandDisabled = true
+}
+
+// IsCommandDisabled returns true if a listener disabled the command
+func (e *Event) IsCommandDisabled() bool {
+	return e.commandDisabled
+}
+
+// StopPropagation prevents the remaining listeners from being called
+func (e *Event) StopPropagation() {
+	e.propagationStopped = true
+}
+
+// IsPropagationStopped returns true if a listener stopped the propagation
+func (e *Event) IsPropagationStopped() bool {
+	return e.propagationStopped
+}
+
+// EventListener is called when an event it listens to is dispatched
+type EventListener func(*Event)
+
+type prioritizedListener struct {
+	listener EventListener
+	priority int
+}
+
+// EventDispatcher calls the listeners registered for an event, by decreasing
+// priority then in registration order. The zero value is ready to use.
+type EventDispatcher struct {
+	mu        sync.RWMutex
+	listeners map[string][]prioritizedListener
+}
+
+// AddListener registers a listener for the given event, listeners with a
+// higher priority are called first
+func (d *EventDispatcher) AddListener(name string, listener EventListener, priority int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.listeners == nil {
+		d.listeners = make(map[string][]prioritizedListener)
+	}
+	listeners := append(d.listeners[name], prioritizedListener{listener, priority})
+	sort.SliceStable(listeners, func(i, j int) bool {
+		return listeners[i].priority > listeners[j].priority
+	})
+	d.listeners[name] = listeners
+}
+
+// HasListeners returns true if at least one listener is registered for the
+// given event
+func (d *EventDispatcher) HasListeners(name string) bool {
+	if d == nil {
+		return false
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return len(d.listeners[name]) > 0
+}
+
+// Dispatch calls the listeners registered for the event until one of them
+// stops the propagation
+func (d *EventDispatcher) Dispatch(e *Event) {
+	if d == nil {
+		return
+	}
+	d.mu.RLock()
+	listeners := d.listeners[e.Name]
+	d.mu.RUnlock()
+
+	for _, l := range listeners {
+		if e.propagationStopped {
+			return
+		}
+		l.listener(e)
+	}
+}
+
+// dispatchCommand dispatches CommandEvent and returns an error if a listener
+// disabled the command
+func (a *Application) dispatchCommand(ctx *Context) error {
+	e := newEvent(CommandEvent, ctx)
+	a.EventDispatcher.Dispatch(e)
+	if e.commandDisabled {
+		return errCommandDisabled
+	}
+	return nil
+}
+
+// dispatchTerminate dispatches ErrorEvent if the execution failed and
+// TerminateEvent, it returns the resulting exit code and error
+func (a *Application) dispatchTerminate(ctx *Context, err error) (int, error) {
+	exitCode := 0
+	disabled := errors.Is(err, errCommandDisabled)
+	if disabled {
+		// errors returned along with the sentinel, by After for instance,
+		// are still reported
+		exitCode, err = CommandDisabledExitCode, withoutCommandDisabled(err)
+	}
+	if err != nil {
+		e := newEvent(ErrorEvent, ctx)
+		e.SetErr(err)
+		if disabled {
+			e.exitCode = CommandDisabledExitCode
+		}
+		a.EventDispatcher.Dispatch(e)
+		exitCode, err = e.exitCode, e.err
+	}
+
+	e := newEvent(TerminateEvent, ctx)
+	e.err, e.exitCode = err, exitCode
+	a.EventDispatcher.Dispatch(e)
+	return e.exitCode, err
+}
+
+// withoutCommandDisabled removes errCommandDisabled from err
+func withoutCommandDisabled(err error) error {
+	m, ok := err.(*multiError)
+	if !ok {
+		return nil
+	}
+	errs := []error{}
+	for _, e := range *m {
+		if e != nil && !errors.Is(e, errCommandDisabled) {
+			errs = append(errs, e)
+		}
+	}
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	return newMultiError(errs...)
+}
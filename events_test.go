@@ -0,0 +1,158 @@
+/*
+ * Copyright (c) 2021-present Fabien Potencier <fabien@symfony.com>
+ *
+ * This file is part of Symfony CLI project
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package console
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestEventDispatcher(t *testing.T) {
+	calls := []string{}
+	dispatcher := &EventDispatcher{}
+	dispatcher.AddListener(CommandEvent, func(e *Event) {
+		calls = append(calls, "low")
+	}, -10)
+	dispatcher.AddListener(CommandEvent, func(e *Event) {
+		calls = append(calls, "default")
+	}, 0)
+	dispatcher.AddListener(CommandEvent, func(e *Event) {
+		calls = append(calls, "high:"+e.Command.FullName())
+		if e.Context.Bool("dry-run") {
+			e.DisableCommand()
+		}
+	}, 10)
+	dispatcher.AddListener(ErrorEvent, func(e *Event) {
+		calls = append(calls, "error:"+e.Err().Error())
+		if e.Command.Name == "recover" {
+			e.SetErr(nil)
+			e.StopPropagation()
+		} else {
+			e.SetErr(Exit("replaced", 5))
+		}
+	}, 0)
+	dispatcher.AddListener(ErrorEvent, func(e *Event) {
+		calls = append(calls, "error:second")
+	}, -1)
+	dispatcher.AddListener(TerminateEvent, func(e *Event) {
+		calls = append(calls, "terminate")
+		if e.Command != nil && e.Command.Name == "ok" {
+			e.SetExitCode(42)
+		}
+	}, 0)
+
+	actionCalled := false
+	app := &Application{
+		Writer:          io.Discard,
+		EventDispatcher: dispatcher,
+		Commands: []*Command{
+			{
+				Name:  "ok",
+				Flags: []Flag{&BoolFlag{Name: "dry-run"}},
+				Action: func(c *Context) error {
+					actionCalled = true
+					return nil
+				},
+			},
+			{
+				Name: "fail",
+				Action: func(c *Context) error {
+					return errors.New("failure")
+				},
+			},
+			{
+				Name: "recover",
+				Action: func(c *Context) error {
+					return errors.New("failure")
+				},
+			},
+		},
+	}
+
+	exitCode, err := app.Execute([]string{"app", "ok"})
+	if err != nil || exitCode != 42 || !actionCalled {
+		t.Errorf("expected the action to be called and exit code 42, got %d and %v", exitCode, err)
+	}
+	if expected := []string{"high:ok", "default", "low", "terminate"}; !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected %v, got %v", expected, calls)
+	}
+
+	calls, actionCalled = calls[:0], false
+	exitCode, err = app.Execute([]string{"app", "ok", "--dry-run"})
+	if err != nil || exitCode != 42 || actionCalled {
+		t.Errorf("expected the command to be disabled, got %d and %v", exitCode, err)
+	}
+	if expected := []string{"high:ok", "default", "low", "terminate"}; !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected %v, got %v", expected, calls)
+	}
+
+	calls = calls[:0]
+	exitCode, err = app.Execute([]string{"app", "fail"})
+	if err == nil || err.Error() != "replaced" || exitCode != 5 {
+		t.Errorf("expected the error to be replaced, got %d and %v", exitCode, err)
+	}
+	if expected := []string{"high:fail", "default", "low", "error:failure", "error:second", "terminate"}; !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected %v, got %v", expected, calls)
+	}
+
+	calls = calls[:0]
+	exitCode, err = app.Execute([]string{"app", "recover"})
+	if err != nil || exitCode != 0 {
+		t.Errorf("expected the error to be removed, got %d and %v", exitCode, err)
+	}
+	if expected := []string{"high:recover", "default", "low", "error:failure", "terminate"}; !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected %v, got %v", expected, calls)
+	}
+}
+
+func TestEventDispatcher_DisabledExitCode(t *testing.T) {
+	dispatcher := &EventDispatcher{}
+	dispatcher.AddListener(CommandEvent, func(e *Event) {
+		e.DisableCommand()
+	}, 0)
+	app := &Application{
+		Writer:          io.Discard,
+		EventDispatcher: dispatcher,
+		Commands: []*Command{
+			{
+				Name: "foo",
+				Action: func(c *Context) error {
+					t.Error("the command should not be executed")
+					return nil
+				},
+			},
+		},
+	}
+
+	exitCode, err := app.Execute([]string{"app", "foo"})
+	if err != nil || exitCode != CommandDisabledExitCode {
+		t.Errorf("expected exit code %d, got %d and %v", CommandDisabledExitCode, exitCode, err)
+	}
+
+	app.After = func(c *Context) error {
+		return errors.New("after failure")
+	}
+	exitCode, err = app.Execute([]string{"app", "foo"})
+	if err == nil || err.Error() != "after failure" || exitCode != CommandDisabledExitCode {
+		t.Errorf("expected exit code %d and the After error, got %d and %v", CommandDisabledExitCode, exitCode, err)
+	}
+}
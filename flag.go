@@ -134,6 +134,11 @@ type Generic interface {
 // Apply takes the flagset and calls Set on the generic flag with the value
 // provided by the user for parsing by the flag
 func (f *GenericFlag) Apply(set *flag.FlagSet) {
+	if f.FromFile {
+		set.Var(&genericFileValue{f.Destination}, f.Name, f.Usage)
+		set.Var(&fileFlagValue{set, f.Name}, f.Name+fileFlagSuffix, "")
+		return
+	}
 	set.Var(f.Destination, f.Name, f.Usage)
 }
 
@@ -141,6 +146,7 @@ func (f *GenericFlag) Apply(set *flag.FlagSet) {
 type StringMap struct {
 	m          map[string]string
 	hasBeenSet bool
+	fromFile   bool
 }
 
 // NewStringMap creates a *StringMap with default values
@@ -150,6 +156,13 @@ func NewStringMap(m map[string]string) *StringMap {
 
 // Set appends the string value to the list of values
 func (m *StringMap) Set(value string) error {
+	if m.fromFile && !strings.HasPrefix(value, slPfx) {
+		var err error
+		if value, err = readFlagValue(value); err != nil {
+			return err
+		}
+	}
+
 	if !m.hasBeenSet {
 		m.m = make(map[string]string)
 		m.hasBeenSet = true
@@ -201,18 +214,28 @@ func (m *StringMap) Value() map[string]string {
 	return m.m
 }
 
+// Get returns the value, it makes the type a flag.Getter
+func (m *StringMap) Get() interface{} {
+	return m.m
+}
+
 // Apply populates the flag given the flag set and environment
 func (f *StringMapFlag) Apply(set *flag.FlagSet) {
 	if f.Destination == nil {
 		f.Destination = NewStringMap(make(map[string]string))
 	}
+	f.Destination.fromFile = f.FromFile
 	set.Var(f.Destination, f.Name, f.Usage)
+	if f.FromFile {
+		set.Var(&fileFlagValue{set, f.Name}, f.Name+fileFlagSuffix, "")
+	}
 }
 
 // StringSlice wraps a []string to satisfy flag.Value
 type StringSlice struct {
 	slice      []string
 	hasBeenSet bool
+	fromFile   bool
 }
 
 // NewStringSlice creates a *StringSlice with default values
@@ -222,6 +245,13 @@ func NewStringSlice(defaults ...string) *StringSlice {
 
 // Set appends the string value to the list of values
 func (f *StringSlice) Set(value string) error {
+	if f.fromFile && !strings.HasPrefix(value, slPfx) {
+		var err error
+		if value, err = readFlagValue(value); err != nil {
+			return err
+		}
+	}
+
 	if !f.hasBeenSet {
 		f.slice = []string{}
 		f.hasBeenSet = true
@@ -254,13 +284,22 @@ func (f *StringSlice) Value() []string {
 	return f.slice
 }
 
+// Get returns the value, it makes the type a flag.Getter
+func (f *StringSlice) Get() interface{} {
+	return f.slice
+}
+
 // Apply populates the flag given the flag set and environment
 func (f *StringSliceFlag) Apply(set *flag.FlagSet) {
 	if f.Destination == nil {
 		f.Destination = NewStringSlice()
 	}
+	f.Destination.fromFile = f.FromFile
 
 	set.Var(f.Destination, f.Name, f.Usage)
+	if f.FromFile {
+		set.Var(&fileFlagValue{set, f.Name}, f.Name+fileFlagSuffix, "")
+	}
 }
 
 // IntSlice wraps an []int to satisfy flag.Value
@@ -328,6 +367,11 @@ func (i *IntSlice) Value() []int {
 	return i.slice
 }
 
+// Get returns the value, it makes the type a flag.Getter
+func (i *IntSlice) Get() interface{} {
+	return i.slice
+}
+
 // Apply populates the flag given the flag set and environment
 func (f *IntSliceFlag) Apply(set *flag.FlagSet) {
 	if f.Destination == nil {
@@ -382,6 +426,11 @@ func (f *Int64Slice) Value() []int64 {
 	return f.slice
 }
 
+// Get returns the value, it makes the type a flag.Getter
+func (f *Int64Slice) Get() interface{} {
+	return f.slice
+}
+
 // Apply populates the flag given the flag set and environment
 func (f *Int64SliceFlag) Apply(set *flag.FlagSet) {
 	if f.Destination == nil {
@@ -398,69 +447,55 @@ func (f *BoolFlag) Apply(set *flag.FlagSet) {
 	} else {
 		set.Bool(f.Name, f.DefaultValue, f.Usage)
 	}
-}
-
-// Apply populates the flag given the flag set and environment
-func (f *StringFlag) Apply(set *flag.FlagSet) {
-	if f.Destination != nil {
-		set.StringVar(f.Destination, f.Name, f.DefaultValue, f.Usage)
-	} else {
-		set.String(f.Name, f.DefaultValue, f.Usage)
+	if f.Negatable {
+		set.Var(&negatedBoolValue{set, f.Name}, negatedFlagPrefix+f.Name, "")
 	}
 }
 
-// Apply populates the flag given the flag set and environment
-func (f *IntFlag) Apply(set *flag.FlagSet) {
-	if f.Destination != nil {
-		set.IntVar(f.Destination, f.Name, f.DefaultValue, f.Usage)
-	} else {
-		set.Int(f.Name, f.DefaultValue, f.Usage)
-	}
-}
+const negatedFlagPrefix = "no-"
 
-// Apply populates the flag given the flag set and environment
-func (f *Int64Flag) Apply(set *flag.FlagSet) {
-	if f.Destination != nil {
-		set.Int64Var(f.Destination, f.Name, f.DefaultValue, f.Usage)
-	} else {
-		set.Int64(f.Name, f.DefaultValue, f.Usage)
-	}
+// negatedBoolValue sets the opposite value to the target flag, the last
+// occurrence of either flag on the command line wins
+type negatedBoolValue struct {
+	set    *flag.FlagSet
+	target string
 }
 
-// Apply populates the flag given the flag set and environment
-func (f *UintFlag) Apply(set *flag.FlagSet) {
-	if f.Destination != nil {
-		set.UintVar(f.Destination, f.Name, f.DefaultValue, f.Usage)
-	} else {
-		set.Uint(f.Name, f.DefaultValue, f.Usage)
-	}
-}
+func (v *negatedBoolValue) IsBoolFlag() bool { return true }
 
-// Apply populates the flag given the flag set and environment
-func (f *Uint64Flag) Apply(set *flag.FlagSet) {
-	if f.Destination != nil {
-		set.Uint64Var(f.Destination, f.Name, f.DefaultValue, f.Usage)
-	} else {
-		set.Uint64(f.Name, f.DefaultValue, f.Usage)
+func (v *negatedBoolValue) Set(s string) error {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return errors.WithStack(err)
 	}
+	return errors.WithStack(v.set.Set(v.target, strconv.FormatBool(!b)))
 }
 
-// Apply populates the flag given the flag set and environment
-func (f *DurationFlag) Apply(set *flag.FlagSet) {
-	if f.Destination != nil {
-		set.DurationVar(f.Destination, f.Name, f.DefaultValue, f.Usage)
-	} else {
-		set.Duration(f.Name, f.DefaultValue, f.Usage)
+func (v *negatedBoolValue) String() string {
+	if v.set == nil {
+		return ""
 	}
+	if f := v.set.Lookup(v.target); f != nil {
+		if b, err := strconv.ParseBool(f.Value.String()); err == nil {
+			return strconv.FormatBool(!b)
+		}
+	}
+	return ""
 }
 
-// Apply populates the flag given the flag set and environment
-func (f *Float64Flag) Apply(set *flag.FlagSet) {
-	if f.Destination != nil {
-		set.Float64Var(f.Destination, f.Name, f.DefaultValue, f.Usage)
-	} else {
-		set.Float64(f.Name, f.DefaultValue, f.Usage)
+// negatedNames returns the --no-<name> forms of a negatable flag
+func negatedNames(f Flag) []string {
+	bf, ok := f.(*BoolFlag)
+	if !ok || !bf.Negatable {
+		return nil
+	}
+	names := []string{}
+	for _, name := range f.Names() {
+		if len(name) > 1 {
+			names = append(names, negatedFlagPrefix+name)
+		}
 	}
+	return names
 }
 
 // NewFloat64Slice makes a *Float64Slice with default values
@@ -513,6 +548,11 @@ func (f *Float64Slice) Value() []float64 {
 	return f.slice
 }
 
+// Get returns the value, it makes the type a flag.Getter
+func (f *Float64Slice) Get() interface{} {
+	return f.slice
+}
+
 // Apply populates the flag given the flag set and environment
 func (f *Float64SliceFlag) Apply(set *flag.FlagSet) {
 	if f.Destination == nil {
@@ -671,6 +711,12 @@ func stringifyFlag(f Flag) string {
 	case *StringMapFlag:
 		return withEnvHint(flagStringSliceField(f, "EnvVars"),
 			stringifyStringMapFlag(f))
+	case *ChoiceSliceFlag:
+		return withEnvHint(flagStringSliceField(f, "EnvVars"),
+			stringifyChoiceSliceFlag(f))
+	case *CountFlag:
+		return withEnvHint(flagStringSliceField(f, "EnvVars"),
+			stringifyCountFlag(f))
 	}
 
 	placeholder, usage := unquoteUsage(fv.FieldByName("Usage").String())
@@ -690,6 +736,12 @@ func stringifyFlag(f Flag) string {
 			defaultValueString = fmt.Sprintf("%v", val.Interface())
 		}
 	}
+	if tf, ok := f.(interface{ defaultText() string }); ok {
+		defaultValueString = tf.defaultText()
+	}
+	if defaultValueString != "" && flagIsSensitive(f) {
+		defaultValueString = redactedValue
+	}
 
 	helpText := fv.FieldByName("DefaultText")
 	if helpText.IsValid() && helpText.String() != "" {
@@ -704,6 +756,10 @@ func stringifyFlag(f Flag) string {
 	if flagIsRequired(f) {
 		requiredString = " <comment>(required)</>"
 	}
+	if choices := fv.FieldByName("Choices"); choices.IsValid() {
+		usage += choicesHint(choices.Interface().([]string))
+	}
+	usage += fromFileHint(f)
 
 	if needsPlaceholder && placeholder == "" {
 		placeholder = defaultPlaceholder
@@ -711,8 +767,19 @@ func stringifyFlag(f Flag) string {
 
 	usageWithDefault := strings.TrimSpace(fmt.Sprintf("%s%s%s", usage, defaultValueString, requiredString))
 
+	names := f.Names()
+	if len(negatedNames(f)) > 0 {
+		names = make([]string, len(f.Names()))
+		for i, name := range f.Names() {
+			if len(name) > 1 {
+				name = "[" + negatedFlagPrefix + "]" + name
+			}
+			names[i] = name
+		}
+	}
+
 	return withEnvHint(flagStringSliceField(f, "EnvVars"),
-		fmt.Sprintf("<info>%s</>\t%s", prefixedNames(f.Names(), placeholder), usageWithDefault))
+		fmt.Sprintf("<info>%s</>\t%s", prefixedNames(names, placeholder), usageWithDefault))
 }
 
 func stringifyIntSliceFlag(f *IntSliceFlag) string {
@@ -757,8 +824,22 @@ func stringifyStringSliceFlag(f *StringSliceFlag) string {
 			}
 		}
 	}
+	if f.Sensitive && len(defaultVals) > 0 {
+		defaultVals = []string{redactedValue}
+	}
 
-	return stringifySliceFlag(f.Usage, f.Names(), defaultVals)
+	return stringifySliceFlag(f.Usage+fromFileHint(f), f.Names(), defaultVals)
+}
+
+func stringifyChoiceSliceFlag(f *ChoiceSliceFlag) string {
+	defaultVals := []string{}
+	if f.Destination != nil {
+		for _, s := range f.Destination.Value() {
+			defaultVals = append(defaultVals, fmt.Sprintf("%q", s))
+		}
+	}
+
+	return stringifySliceFlag(f.Usage+choicesHint(f.Choices), f.Names(), defaultVals)
 }
 
 func stringifySliceFlag(usage string, names, defaultVals []string) string {
@@ -777,7 +858,14 @@ func stringifySliceFlag(usage string, names, defaultVals []string) string {
 }
 
 func stringifyStringMapFlag(f *StringMapFlag) string {
-	return stringifyMapFlag(f.Usage, f.Names(), f.Destination)
+	if f.Sensitive && f.Destination != nil {
+		masked := make(map[string]string)
+		for key := range f.Destination.Value() {
+			masked[key] = redactedValue
+		}
+		return stringifyMapFlag(f.Usage+fromFileHint(f), f.Names(), NewStringMap(masked))
+	}
+	return stringifyMapFlag(f.Usage+fromFileHint(f), f.Names(), f.Destination)
 }
 
 func stringifyMapFlag(usage string, names []string, defaultVals fmt.Stringer) string {
@@ -0,0 +1,125 @@
+/*
+ * Copyright (c) 2021-present Fabien Potencier <fabien@symfony.com>
+ *
+ * This file is part of Symfony CLI project
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package console
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/symfony-cli/terminal"
+)
+
+const fileFlagSuffix = "-file"
+
+// readFlagValue returns the content of the file when the value is "@path",
+// of stdin when it is "-" and the value itself otherwise, trailing newlines
+// being trimmed
+func readFlagValue(value string) (string, error) {
+	var (
+		content []byte
+		err     error
+	)
+	switch {
+	case value == "-":
+		content, err = io.ReadAll(terminal.Stdin)
+	case strings.HasPrefix(value, "@"):
+		content, err = os.ReadFile(ExpandHome(value[1:]))
+	default:
+		return value, nil
+	}
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+// flagReadsFromFile reports whether the flag accepts values read from files
+func flagReadsFromFile(f Flag) bool {
+	field := flagValue(f).FieldByName("FromFile")
+	if field.IsValid() && field.Kind() == reflect.Bool {
+		return field.Bool()
+	}
+
+	return false
+}
+
+func fromFileHint(f Flag) string {
+	if !flagReadsFromFile(f) {
+		return ""
+	}
+	return fmt.Sprintf(" <comment>(reads @path, - for stdin or --%s%s)</>", flagName(f), fileFlagSuffix)
+}
+
+// fileFlagNames returns the --<name>-file companions of a flag reading its
+// value from files
+func fileFlagNames(f Flag) []string {
+	if !flagReadsFromFile(f) {
+		return nil
+	}
+	names := []string{}
+	for _, name := range f.Names() {
+		if len(name) > 1 {
+			names = append(names, name+fileFlagSuffix)
+		}
+	}
+	return names
+}
+
+// fileValue is implemented by the flag values which can be read from a file
+// or stdin
+type fileValue interface {
+	valueReadFromFile() bool
+}
+
+// fileFlagValue sets the target flag to the content of the file given as
+// value
+type fileFlagValue struct {
+	set    *flag.FlagSet
+	target string
+}
+
+func (v *fileFlagValue) Set(path string) error {
+	if path != "-" {
+		path = "@" + path
+	}
+	return errors.WithStack(v.set.Set(v.target, path))
+}
+
+func (v *fileFlagValue) String() string {
+	return ""
+}
+
+// genericFileValue reads the value of a GenericFlag from a file or stdin
+type genericFileValue struct {
+	Generic
+}
+
+func (v *genericFileValue) Set(value string) error {
+	value, err := readFlagValue(value)
+	if err != nil {
+		return err
+	}
+	return v.Generic.Set(value)
+}
@@ -0,0 +1,176 @@
+/*
+ * Copyright (c) 2021-present Fabien Potencier <fabien@symfony.com>
+ *
+ * This file is part of Symfony CLI project
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package console
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/symfony-cli/terminal"
+)
+
+func TestFlagValueFromFile(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	if err := os.WriteFile(tokenFile, []byte("s3cr3t\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	countFile := filepath.Join(dir, "count")
+	if err := os.WriteFile(countFile, []byte("42\r\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_TOKEN", "")
+
+	var token, label string
+	var count int
+	app := &Application{
+		Writer: io.Discard,
+		Commands: []*Command{
+			{
+				Name: "login",
+				Flags: []Flag{
+					&StringFlag{Name: "token", Aliases: []string{"t"}, EnvVars: []string{"TEST_TOKEN"}, FromFile: true, Required: true},
+					&IntFlag{Name: "count", FromFile: true},
+					&StringFlag{Name: "label"},
+				},
+				Action: func(c *Context) error {
+					token, count, label = c.String("token"), c.Int("count"), c.String("label")
+					return nil
+				},
+			},
+		},
+	}
+
+	for args, expected := range map[string]string{
+		"--token=@" + tokenFile:     "s3cr3t",
+		"-t @" + tokenFile:          "s3cr3t",
+		"--token-file " + tokenFile: "s3cr3t",
+		"--token-file=" + tokenFile: "s3cr3t",
+		"--token plain":             "plain",
+	} {
+		token, count, label = "", 0, ""
+		if _, err := app.Execute(append([]string{"app", "login"}, strings.Fields(args)...)); err != nil {
+			t.Fatalf("%s: %v", args, err)
+		}
+		if token != expected {
+			t.Errorf("%s: expected %q, got %q", args, expected, token)
+		}
+	}
+
+	if _, err := app.Execute([]string{"app", "login", "--token=x", "--label=@foo.txt"}); err != nil {
+		t.Fatal(err)
+	} else if label != "@foo.txt" {
+		t.Errorf(`expected flags without FromFile to be left untouched, got %q`, label)
+	}
+
+	if _, err := app.Execute([]string{"app", "login", "--token=x", "--count=@" + countFile}); err != nil {
+		t.Fatal(err)
+	} else if count != 42 {
+		t.Errorf("expected 42, got %d", count)
+	}
+
+	homeFile := filepath.Join(dir, "home")
+	if err := os.WriteFile(homeFile, []byte("~/s3cr3t\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Execute([]string{"app", "login", "--token-file", homeFile}); err != nil {
+		t.Fatal(err)
+	} else if token != "~/s3cr3t" {
+		t.Errorf(`expected values read from files not to be expanded, got %q`, token)
+	}
+
+	terminal.Stdin.SetReader(strings.NewReader("from stdin\n\n"))
+	defer terminal.Stdin.SetReader(os.Stdin)
+	if _, err := app.Execute([]string{"app", "login", "--token", "-"}); err != nil {
+		t.Fatal(err)
+	} else if token != "from stdin" {
+		t.Errorf(`expected "from stdin", got %q`, token)
+	}
+
+	t.Setenv("TEST_TOKEN", "@"+tokenFile)
+	if _, err := app.Execute([]string{"app", "login"}); err != nil {
+		t.Fatal(err)
+	} else if token != "s3cr3t" {
+		t.Errorf(`expected "s3cr3t", got %q`, token)
+	}
+
+	_, err := app.Execute([]string{"app", "login", "--token-file", filepath.Join(dir, "missing")})
+	if err == nil || !strings.Contains(err.Error(), "no such file or directory") {
+		t.Errorf("expected an error for a missing file, got %v", err)
+	}
+
+	f := app.Command("login").Flags[0]
+	if expected := "<info>--token=value, -t=value</>\t<comment>(reads @path, - for stdin or --token-file)</> <comment>(required)</>"; !strings.HasPrefix(f.String(), expected) {
+		t.Errorf("expected %q, got %q", expected, f.String())
+	}
+}
+
+func TestCollectionFlagValuesFromFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	keyFile := write("key", "k3y\n")
+	secretFile := write("secret", "API_KEY=s3cr3t\n")
+	serveFile := write("serve", "10,20\n")
+
+	var keys []string
+	var secrets map[string]string
+	var serve interface{}
+	keyFlag := &StringSliceFlag{Name: "key", FromFile: true}
+	app := &Application{
+		Writer: io.Discard,
+		Flags: []Flag{
+			keyFlag,
+			&StringMapFlag{Name: "secret", FromFile: true},
+			&GenericFlag{Name: "serve", Destination: &Parser{}, FromFile: true},
+		},
+		Action: func(c *Context) error {
+			keys, secrets, serve = c.StringSlice("key"), c.StringMap("secret"), c.Generic("serve")
+			return nil
+		},
+	}
+
+	args := []string{"app", "--key=@" + keyFile, "--key-file", keyFile, "--key=plain", "--secret-file", secretFile, "--serve=@" + serveFile}
+	if _, err := app.Execute(args); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"k3y", "k3y", "plain"}; !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected %v, got %v", expected, keys)
+	}
+	if expected := map[string]string{"API_KEY": "s3cr3t"}; !reflect.DeepEqual(secrets, expected) {
+		t.Errorf("expected %v, got %v", expected, secrets)
+	}
+	if expected := (&Parser{"10", "20"}); !reflect.DeepEqual(serve, expected) {
+		t.Errorf("expected %v, got %v", expected, serve)
+	}
+
+	if expected := "<info>--key=value</>\t<comment>(reads @path, - for stdin or --key-file)</>"; !strings.HasPrefix(keyFlag.String(), expected) {
+		t.Errorf("expected %q, got %q", expected, keyFlag.String())
+	}
+}
@@ -0,0 +1,218 @@
+/*
+ * Copyright (c) 2021-present Fabien Potencier <fabien@symfony.com>
+ *
+ * This file is part of Symfony CLI project
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package console
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// FlagGroupKind defines the constraint applied to the flags of a FlagGroup
+type FlagGroupKind int
+
+const (
+	// FlagsMutuallyExclusive allows at most one of the flags to be set.
+	// Values coming from environment variables or configuration sources are
+	// not taken into account as users might not be aware of them.
+	FlagsMutuallyExclusive FlagGroupKind = iota
+	// FlagsRequiredTogether requires the flags to be all set or none of them
+	FlagsRequiredTogether
+	// FlagsOneRequired requires at least one of the flags to be set
+	FlagsOneRequired
+	// FlagsRequiredIf requires all the flags to be set when the If flag
+	// value equals Value
+	FlagsRequiredIf
+)
+
+// FlagGroup is a constraint on a set of flags of a command, it also applies
+// to the subcommands
+type FlagGroup struct {
+	Kind  FlagGroupKind
+	Flags []string
+	// If and Value define the condition of a FlagsRequiredIf group
+	If    string
+	Value string
+}
+
+func (g FlagGroup) String() string {
+	names := make([]string, len(g.Flags))
+	for i, name := range g.Flags {
+		names[i] = fmt.Sprintf("<info>%s%s</>", prefixFor(name), name)
+	}
+	list := strings.Join(names, ", ")
+
+	switch g.Kind {
+	case FlagsMutuallyExclusive:
+		return fmt.Sprintf("%s cannot be used together", list)
+	case FlagsRequiredTogether:
+		return fmt.Sprintf("%s must be used together", list)
+	case FlagsOneRequired:
+		return fmt.Sprintf("at least one of %s is required", list)
+	case FlagsRequiredIf:
+		return fmt.Sprintf("%s required when <info>%s%s</> is %q", list, prefixFor(g.If), g.If, g.Value)
+	}
+	return list
+}
+
+func (g FlagGroup) validate(flags []Flag) error {
+	min := 2
+	if g.Kind == FlagsRequiredIf {
+		min = 1
+		if findFlag(flags, g.If) == nil {
+			return errors.Errorf(`flag group refers to an undefined flag "%s"`, g.If)
+		}
+	}
+	if len(g.Flags) < min {
+		return errors.Errorf("flag group %v must contain at least %d flags", g.canonical(flags).Flags, min)
+	}
+	for _, name := range g.Flags {
+		if findFlag(flags, name) == nil {
+			return errors.Errorf(`flag group refers to an undefined flag "%s"`, name)
+		}
+	}
+	return nil
+}
+
+func (g FlagGroup) check(flags []Flag, set *flag.FlagSet, sources flagSources) error {
+	visited := make(map[string]bool)
+	set.Visit(func(f *flag.Flag) {
+		if g.Kind == FlagsMutuallyExclusive {
+			if kind := sources[f.Name].Kind; kind == FlagSourceEnv || kind == FlagSourceConfig {
+				return
+			}
+		}
+		visited[f.Name] = true
+	})
+
+	g = g.canonical(flags)
+	present := []string{}
+	missing := []string{}
+	for _, name := range g.Flags {
+		if visited[name] {
+			present = append(present, name)
+		} else {
+			missing = append(missing, name)
+		}
+	}
+
+	switch g.Kind {
+	case FlagsMutuallyExclusive:
+		if len(present) > 1 {
+			return errors.Errorf("Flags %s cannot be used together", quoteNames(present))
+		}
+	case FlagsRequiredTogether:
+		if len(present) > 0 && len(missing) > 0 {
+			return errors.Errorf("Flags %s must be used together, %s not set", quoteNames(append(present, missing...)), quoteNames(missing))
+		}
+	case FlagsOneRequired:
+		if len(present) == 0 {
+			return errors.Errorf("At least one of the flags %s is required", quoteNames(g.Flags))
+		}
+	case FlagsRequiredIf:
+		if f := set.Lookup(g.If); f != nil && f.Value.String() == g.Value && len(missing) > 0 {
+			return errors.Errorf(`Required flag "%s" is not set when "%s" is "%s"`, missing[0], g.If, g.Value)
+		}
+	}
+	return nil
+}
+
+// canonical returns a copy of the group referring to the flags by their main
+// name
+func (g FlagGroup) canonical(flags []Flag) FlagGroup {
+	names := make([]string, len(g.Flags))
+	for i, name := range g.Flags {
+		names[i] = canonicalFlagName(flags, name)
+	}
+	g.Flags = names
+	if g.If != "" {
+		g.If = canonicalFlagName(flags, g.If)
+	}
+	return g
+}
+
+// canonicalFlagName returns the main name of a flag given any of its names
+func canonicalFlagName(flags []Flag, name string) string {
+	if f := findFlag(flags, name); f != nil {
+		return flagName(f)
+	}
+	return name
+}
+
+func quoteNames(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = fmt.Sprintf(`"%s"`, name)
+	}
+	if len(quoted) == 1 {
+		return quoted[0]
+	}
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " and " + quoted[len(quoted)-1]
+}
+
+// definedFlagGroups returns the flag groups of the command and the ones of
+// its parents
+func (c *Command) definedFlagGroups() []FlagGroup {
+	groups := []FlagGroup{}
+	for _, cmd := range append(c.parents(), c) {
+		groups = append(groups, cmd.FlagGroups...)
+	}
+	return groups
+}
+
+// VisibleFlagGroups returns the flag groups applying to the command whose
+// flags are all visible
+func (c *Command) VisibleFlagGroups() []FlagGroup {
+	visible := visibleFlags(c.definedFlags())
+	groups := []FlagGroup{}
+	for _, g := range c.definedFlagGroups() {
+		isVisible := g.Kind != FlagsRequiredIf || findFlag(visible, g.If) != nil
+		for _, name := range g.Flags {
+			if findFlag(visible, name) == nil {
+				isVisible = false
+			}
+		}
+		if isVisible {
+			groups = append(groups, g.canonical(visible))
+		}
+	}
+	return groups
+}
+
+func checkFlagGroupsDefinition(c *Command) {
+	flags := c.definedFlags()
+	for _, g := range c.definedFlagGroups() {
+		if err := g.validate(flags); err != nil {
+			panic(fmt.Sprintf("command %s: %s", c.FullName(), err))
+		}
+	}
+}
+
+func checkFlagGroups(c *Command, set *flag.FlagSet, sources flagSources) error {
+	flags := c.definedFlags()
+	for _, g := range c.definedFlagGroups() {
+		if err := g.check(flags, set, sources); err != nil {
+			return err
+		}
+	}
+	return nil
+}
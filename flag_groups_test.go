@@ -0,0 +1,120 @@
+/*
+ * Copyright (c) 2021-present Fabien Potencier <fabien@symfony.com>
+ *
+ * This file is part of Symfony CLI project
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package console
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestFlagGroups(t *testing.T) {
+	newApp := func(w io.Writer) *Application {
+		return &Application{
+			Writer: w,
+			Commands: []*Command{
+				{
+					Name: "export",
+					Flags: []Flag{
+						&BoolFlag{Name: "json"},
+						&BoolFlag{Name: "yaml", Aliases: []string{"y"}, EnvVars: []string{"EXPORT_YAML"}},
+						&StringFlag{Name: "user"},
+						&StringFlag{Name: "password"},
+						&StringFlag{Name: "file", Aliases: []string{"f"}},
+						&StringFlag{Name: "url"},
+						&StringFlag{Name: "mode", Aliases: []string{"m"}, DefaultValue: "local"},
+						&StringFlag{Name: "token"},
+					},
+					FlagGroups: []FlagGroup{
+						{Kind: FlagsMutuallyExclusive, Flags: []string{"json", "y"}},
+						{Kind: FlagsRequiredTogether, Flags: []string{"user", "password"}},
+						{Kind: FlagsOneRequired, Flags: []string{"f", "url"}},
+						{Kind: FlagsRequiredIf, Flags: []string{"token"}, If: "m", Value: "remote"},
+					},
+					Action: func(c *Context) error { return nil },
+				},
+			},
+		}
+	}
+
+	for args, expected := range map[string]string{
+		"--file=a":                                     "",
+		"--url=a --json --user=u --password=p":         "",
+		"--file=a --json --yaml":                       `Flags "json" and "yaml" cannot be used together`,
+		"--file=a -y --json":                           `Flags "json" and "yaml" cannot be used together`,
+		"--file=a --password=p":                        `Flags "password" and "user" must be used together, "user" not set`,
+		"--json":                                       `At least one of the flags "file" and "url" is required`,
+		"--file=a --mode=remote":                       `Required flag "token" is not set when "mode" is "remote"`,
+		"--file=a --mode=remote --token=secret":        "",
+		"--file=a --mode=remote --token=secret --url=": "",
+	} {
+		_, err := newApp(io.Discard).Execute(append([]string{"app", "export"}, strings.Fields(args)...))
+		if expected == "" {
+			if err != nil {
+				t.Errorf("%s: expected no error, got %v", args, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: expected error %q, got %v", args, expected, err)
+		}
+	}
+
+	// only the values given on the command line are mutually exclusive
+	t.Setenv("EXPORT_YAML", "true")
+	if _, err := newApp(io.Discard).Execute([]string{"app", "export", "--file=a", "--json"}); err != nil {
+		t.Errorf("expected values from the environment to be ignored, got %v", err)
+	}
+
+	buf := new(bytes.Buffer)
+	if _, err := newApp(buf).Execute([]string{"app", "help", "export"}); err != nil {
+		t.Fatal(err)
+	}
+	expected := `<comment>Option constraints:</>
+  <info>--json</>, <info>--yaml</> cannot be used together
+  <info>--user</>, <info>--password</> must be used together
+  at least one of <info>--file</>, <info>--url</> is required
+  <info>--token</> required when <info>--mode</> is "remote"
+`
+	if !strings.Contains(buf.String(), expected) {
+		t.Errorf("expected the help to contain %q, got %q", expected, buf.String())
+	}
+}
+
+func TestFlagGroups_Invalid(t *testing.T) {
+	for group, expected := range map[*FlagGroup]string{
+		{Kind: FlagsMutuallyExclusive, Flags: []string{"j"}}:         "command export: flag group [json] must contain at least 2 flags",
+		{Kind: FlagsOneRequired, Flags: []string{"json", "xml"}}:     `command export: flag group refers to an undefined flag "xml"`,
+		{Kind: FlagsRequiredIf, Flags: []string{"json"}, If: "mode"}: `command export: flag group refers to an undefined flag "mode"`,
+	} {
+		func() {
+			defer func() {
+				if got := recover(); got != expected {
+					t.Errorf("expected %v to panic with %q, got %v", *group, expected, got)
+				}
+			}()
+			app := &Application{
+				Commands: []*Command{
+					{Name: "export", Flags: []Flag{&BoolFlag{Name: "json", Aliases: []string{"j"}}, &BoolFlag{Name: "yaml"}}, FlagGroups: []FlagGroup{*group}},
+				},
+			}
+			app.setup()
+		}()
+	}
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright (c) 2021-present Fabien Potencier <fabien@symfony.com>
+ *
+ * This file is part of Symfony CLI project
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package console
+
+import "fmt"
+
+// FlagSourceKind tells where the value of a flag comes from
+type FlagSourceKind int
+
+const (
+	// FlagSourceDefault is used when the flag is not set
+	FlagSourceDefault FlagSourceKind = iota
+	// FlagSourceCommandLine is used when the flag is given on the command
+	// line
+	FlagSourceCommandLine
+	// FlagSourceEnv is used when the value is read from an environment
+	// variable
+	FlagSourceEnv
+	// FlagSourceConfig is used when the value is read from one of the
+	// application ConfigSources
+	FlagSourceConfig
+	// FlagSourceInteractive is used when the value is an answer given to an
+	// interactive prompt
+	FlagSourceInteractive
+	// FlagSourceProgrammatic is used when the value is set with Context.Set
+	FlagSourceProgrammatic
+)
+
+func (k FlagSourceKind) String() string {
+	switch k {
+	case FlagSourceCommandLine:
+		return "command line"
+	case FlagSourceEnv:
+		return "environment"
+	case FlagSourceConfig:
+		return "configuration"
+	case FlagSourceInteractive:
+		return "interactive prompt"
+	case FlagSourceProgrammatic:
+		return "program"
+	default:
+		return "default"
+	}
+}
+
+// FlagSource describes where the value of a flag comes from
+type FlagSource struct {
+	Kind FlagSourceKind
+	// Name is the environment variable name or the configuration key
+	Name string
+	// File is the configuration file the value was read from, if any
+	File string
+}
+
+func (s FlagSource) String() string {
+	switch s.Kind {
+	case FlagSourceEnv:
+		return fmt.Sprintf("environment variable %s", s.Name)
+	case FlagSourceConfig:
+		if s.File != "" {
+			return fmt.Sprintf("configuration key %q in %s", s.Name, s.File)
+		}
+		return fmt.Sprintf("configuration key %q", s.Name)
+	default:
+		return s.Kind.String()
+	}
+}
+
+// flagSources holds the sources of the flags not set on the command line,
+// indexed by flag name
+type flagSources map[string]FlagSource
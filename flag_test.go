@@ -1555,3 +1555,50 @@ func TestBlackfireCurlArgsParsing(t *testing.T) {
 		t.Fatal("Action didn't run")
 	}
 }
+
+func TestNegatableBoolFlag(t *testing.T) {
+	var cache, color bool
+	var cacheSet bool
+	app := &Application{
+		Flags: []Flag{&BoolFlag{Name: "color", DefaultValue: true, Negatable: true}},
+		Commands: []*Command{
+			{
+				Name:  "build",
+				Flags: []Flag{&BoolFlag{Name: "cache", Aliases: []string{"c"}, Negatable: true}},
+				Action: func(c *Context) error {
+					cache, cacheSet, color = c.Bool("cache"), c.IsSet("cache"), c.Bool("color")
+					return nil
+				},
+			},
+		},
+	}
+
+	for args, expected := range map[string][3]bool{
+		"build":                          {false, false, true},
+		"build --cache":                  {true, true, true},
+		"build --no-cache":               {false, true, true},
+		"build --cache --no-cache":       {false, true, true},
+		"build --no-cache -c":            {true, true, true},
+		"build --no-cache=false":         {true, true, true},
+		"--no-color build":               {false, false, false},
+		"build --no-color --color":       {false, false, true},
+		"--color=false build --no-cache": {false, true, false},
+	} {
+		if err := app.Run(append([]string{"app"}, strings.Fields(args)...)); err != nil {
+			t.Fatalf("%s: %v", args, err)
+		}
+		if got := [3]bool{cache, cacheSet, color}; got != expected {
+			t.Errorf("%s: expected %v, got %v", args, expected, got)
+		}
+	}
+
+	f := &BoolFlag{Name: "cache", Aliases: []string{"c"}, Usage: "Use the cache", Negatable: true}
+	if expected := "<info>--[no-]cache, -c</>\tUse the cache"; f.String() != expected {
+		t.Errorf("expected %q, got %q", expected, f.String())
+	}
+
+	ctx := NewContext(app, nil, nil)
+	if candidates, _ := shellCompletions(ctx, "build --no-c"); !reflect.DeepEqual(candidates, []string{"--no-cache", "--no-color"}) {
+		t.Errorf("expected the negated flags to be completed, got %v", candidates)
+	}
+}
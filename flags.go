@@ -21,13 +21,13 @@ package console
 
 import (
 	"flag"
-	"strconv"
 	"time"
 
 	"github.com/posener/complete"
 )
 
-// BoolFlag is a flag with type bool
+// BoolFlag is a flag with type bool, when Negatable is set a --no-<name>
+// flag setting the value to false is also registered
 type BoolFlag struct {
 	Name          string
 	Aliases       []string
@@ -37,6 +37,7 @@ type BoolFlag struct {
 	DefaultValue  bool
 	DefaultText   string
 	Required      bool
+	Negatable     bool
 	ArgsPredictor func(*Context, complete.Args) []string
 	Validator     func(*Context, bool) error
 	Destination   *bool
@@ -70,147 +71,29 @@ func (f *BoolFlag) Names() []string {
 // Bool looks up the value of a local BoolFlag, returns
 // false if not found
 func (c *Context) Bool(name string) bool {
-	if f := lookupRawFlag(name, c); f != nil {
-		return lookupBool(name, f)
-	}
-	return false
-}
-
-func lookupBool(name string, f *flag.Flag) bool {
-	if f == nil {
-		return false
-	}
-
-	if parsed, err := strconv.ParseBool(f.Value.String()); err == nil {
-		return parsed
-	}
-
-	return false
+	return lookupFlagValue[bool](c, name)
 }
 
 // DurationFlag is a flag with type time.Duration (see https://golang.org/pkg/time/#ParseDuration)
-type DurationFlag struct {
-	Name          string
-	Aliases       []string
-	Usage         string
-	EnvVars       []string
-	Hidden        bool
-	DefaultValue  time.Duration
-	DefaultText   string
-	Required      bool
-	ArgsPredictor func(*Context, complete.Args) []string
-	Validator     func(*Context, time.Duration) error
-	Destination   *time.Duration
-}
-
-// String returns a readable representation of this value
-// (for usage defaults)
-func (f *DurationFlag) String() string {
-	return FlagStringer(f)
-}
-
-func (f *DurationFlag) PredictArgs(c *Context, a complete.Args) []string {
-	if f.ArgsPredictor != nil {
-		return f.ArgsPredictor(c, a)
-	}
-	return []string{}
-}
-
-func (f *DurationFlag) Validate(c *Context) error {
-	if f.Validator != nil {
-		return f.Validator(c, c.Duration(f.Name))
-	}
-	return nil
-}
-
-// Names returns the names of the flag
-func (f *DurationFlag) Names() []string {
-	return flagNames(f)
-}
+type DurationFlag = TypedFlag[time.Duration]
 
 // Duration looks up the value of a local DurationFlag, returns
 // 0 if not found
 func (c *Context) Duration(name string) time.Duration {
-	if f := lookupRawFlag(name, c); f != nil {
-		return lookupDuration(name, f)
-	}
-	return 0
-}
-
-func lookupDuration(name string, f *flag.Flag) time.Duration {
-	if f == nil {
-		return 0
-	}
-
-	if parsed, err := time.ParseDuration(f.Value.String()); err == nil {
-		return parsed
-	}
-
-	return 0
+	return lookupFlagValue[time.Duration](c, name)
 }
 
 // Float64Flag is a flag with type float64
-type Float64Flag struct {
-	Name          string
-	Aliases       []string
-	Usage         string
-	EnvVars       []string
-	Hidden        bool
-	DefaultValue  float64
-	DefaultText   string
-	Required      bool
-	ArgsPredictor func(*Context, complete.Args) []string
-	Validator     func(*Context, float64) error
-	Destination   *float64
-}
-
-// String returns a readable representation of this value
-// (for usage defaults)
-func (f *Float64Flag) String() string {
-	return FlagStringer(f)
-}
-
-func (f *Float64Flag) PredictArgs(c *Context, a complete.Args) []string {
-	if f.ArgsPredictor != nil {
-		return f.ArgsPredictor(c, a)
-	}
-	return []string{}
-}
-
-func (f *Float64Flag) Validate(c *Context) error {
-	if f.Validator != nil {
-		return f.Validator(c, c.Float64(f.Name))
-	}
-	return nil
-}
-
-// Names returns the names of the flag
-func (f *Float64Flag) Names() []string {
-	return flagNames(f)
-}
+type Float64Flag = TypedFlag[float64]
 
 // Float64 looks up the value of a local Float64Flag, returns
 // 0 if not found
 func (c *Context) Float64(name string) float64 {
-	if f := lookupRawFlag(name, c); f != nil {
-		return lookupFloat64(name, f)
-	}
-	return 0
+	return lookupFlagValue[float64](c, name)
 }
 
-func lookupFloat64(name string, f *flag.Flag) float64 {
-	if f == nil {
-		return 0
-	}
-
-	if parsed, err := strconv.ParseFloat(f.Value.String(), 64); err == nil {
-		return parsed
-	}
-
-	return 0
-}
-
-// GenericFlag is a flag with type Generic
+// GenericFlag is a flag with type Generic. When FromFile is set, "@path" and
+// "-" values are replaced by the content of the file or of stdin.
 type GenericFlag struct {
 	Name          string
 	Aliases       []string
@@ -219,6 +102,8 @@ type GenericFlag struct {
 	Hidden        bool
 	DefaultText   string
 	Required      bool
+	FromFile      bool
+	Sensitive     bool
 	ArgsPredictor func(*Context, complete.Args) []string
 	Validator     func(*Context, interface{}) error
 	Destination   Generic
@@ -263,133 +148,28 @@ func lookupGeneric(name string, f *flag.Flag) interface{} {
 		return nil
 	}
 
-	if parsed, err := f.Value, error(nil); err == nil {
-		return parsed
+	if v, ok := f.Value.(*genericFileValue); ok {
+		return v.Generic
 	}
-
-	return nil
+	return f.Value
 }
 
 // Int64Flag is a flag with type int64
-type Int64Flag struct {
-	Name          string
-	Aliases       []string
-	Usage         string
-	EnvVars       []string
-	Hidden        bool
-	DefaultValue  int64
-	DefaultText   string
-	Required      bool
-	ArgsPredictor func(*Context, complete.Args) []string
-	Validator     func(*Context, int64) error
-	Destination   *int64
-}
-
-// String returns a readable representation of this value
-// (for usage defaults)
-func (f *Int64Flag) String() string {
-	return FlagStringer(f)
-}
-
-func (f *Int64Flag) PredictArgs(c *Context, a complete.Args) []string {
-	if f.ArgsPredictor != nil {
-		return f.ArgsPredictor(c, a)
-	}
-	return []string{}
-}
-
-func (f *Int64Flag) Validate(c *Context) error {
-	if f.Validator != nil {
-		return f.Validator(c, c.Int64(f.Name))
-	}
-	return nil
-}
-
-// Names returns the names of the flag
-func (f *Int64Flag) Names() []string {
-	return flagNames(f)
-}
+type Int64Flag = TypedFlag[int64]
 
 // Int64 looks up the value of a local Int64Flag, returns
 // 0 if not found
 func (c *Context) Int64(name string) int64 {
-	if f := lookupRawFlag(name, c); f != nil {
-		return lookupInt64(name, f)
-	}
-	return 0
-}
-
-func lookupInt64(name string, f *flag.Flag) int64 {
-	if f == nil {
-		return 0
-	}
-
-	if parsed, err := strconv.ParseInt(f.Value.String(), 0, 64); err == nil {
-		return parsed
-	}
-
-	return 0
+	return lookupFlagValue[int64](c, name)
 }
 
 // IntFlag is a flag with type int
-type IntFlag struct {
-	Name          string
-	Aliases       []string
-	Usage         string
-	EnvVars       []string
-	Hidden        bool
-	DefaultValue  int
-	DefaultText   string
-	Required      bool
-	ArgsPredictor func(*Context, complete.Args) []string
-	Validator     func(*Context, int) error
-	Destination   *int
-}
-
-// String returns a readable representation of this value
-// (for usage defaults)
-func (f *IntFlag) String() string {
-	return FlagStringer(f)
-}
-
-func (f *IntFlag) PredictArgs(c *Context, a complete.Args) []string {
-	if f.ArgsPredictor != nil {
-		return f.ArgsPredictor(c, a)
-	}
-	return []string{}
-}
-
-func (f *IntFlag) Validate(c *Context) error {
-	if f.Validator != nil {
-		return f.Validator(c, c.Int(f.Name))
-	}
-	return nil
-}
-
-// Names returns the names of the flag
-func (f *IntFlag) Names() []string {
-	return flagNames(f)
-}
+type IntFlag = TypedFlag[int]
 
 // Int looks up the value of a local IntFlag, returns
 // 0 if not found
 func (c *Context) Int(name string) int {
-	if f := lookupRawFlag(name, c); f != nil {
-		return lookupInt(name, f)
-	}
-	return 0
-}
-
-func lookupInt(name string, f *flag.Flag) int {
-	if f == nil {
-		return 0
-	}
-
-	if parsed, err := strconv.ParseInt(f.Value.String(), 0, 64); err == nil {
-		return int(parsed)
-	}
-
-	return 0
+	return lookupFlagValue[int](c, name)
 }
 
 // IntSliceFlag is a flag with type *IntSlice
@@ -579,67 +359,16 @@ func lookupFloat64Slice(name string, f *flag.Flag) []float64 {
 }
 
 // StringFlag is a flag with type string
-type StringFlag struct {
-	Name          string
-	Aliases       []string
-	Usage         string
-	EnvVars       []string
-	Hidden        bool
-	DefaultValue  string
-	DefaultText   string
-	Required      bool
-	ArgsPredictor func(*Context, complete.Args) []string
-	Validator     func(*Context, string) error
-	Destination   *string
-}
-
-// String returns a readable representation of this value
-// (for usage defaults)
-func (f *StringFlag) String() string {
-	return FlagStringer(f)
-}
-
-func (f *StringFlag) PredictArgs(c *Context, a complete.Args) []string {
-	if f.ArgsPredictor != nil {
-		return f.ArgsPredictor(c, a)
-	}
-	return []string{}
-}
-
-func (f *StringFlag) Validate(c *Context) error {
-	if f.Validator != nil {
-		return f.Validator(c, c.String(f.Name))
-	}
-	return nil
-}
-
-// Names returns the names of the flag
-func (f *StringFlag) Names() []string {
-	return flagNames(f)
-}
+type StringFlag = TypedFlag[string]
 
 // String looks up the value of a local StringFlag, returns
 // "" if not found
 func (c *Context) String(name string) string {
-	if f := lookupRawFlag(name, c); f != nil {
-		return lookupString(name, f)
-	}
-	return ""
+	return lookupFlagValue[string](c, name)
 }
 
-func lookupString(name string, f *flag.Flag) string {
-	if f == nil {
-		return ""
-	}
-
-	if parsed, err := f.Value.String(), error(nil); err == nil {
-		return parsed
-	}
-
-	return ""
-}
-
-// StringSliceFlag is a flag with type *StringSlice
+// StringSliceFlag is a flag with type *StringSlice. When FromFile is set,
+// "@path" and "-" values are replaced by the content of the file or of stdin.
 type StringSliceFlag struct {
 	Name          string
 	Aliases       []string
@@ -648,6 +377,8 @@ type StringSliceFlag struct {
 	Hidden        bool
 	DefaultText   string
 	Required      bool
+	FromFile      bool
+	Sensitive     bool
 	ArgsPredictor func(*Context, complete.Args) []string
 	Validator     func(*Context, []string) error
 	Destination   *StringSlice
@@ -701,7 +432,9 @@ func lookupStringSlice(name string, f *flag.Flag) []string {
 	return nil
 }
 
-// StringMapFlag is a flag with type *StringMap
+// StringMapFlag is a flag with type *StringMap. When FromFile is set, "@path"
+// and "-" values are replaced by the content of the file or of stdin, which
+// must be a key=value pair.
 type StringMapFlag struct {
 	Name          string
 	Aliases       []string
@@ -710,6 +443,8 @@ type StringMapFlag struct {
 	Hidden        bool
 	DefaultText   string
 	Required      bool
+	FromFile      bool
+	Sensitive     bool
 	ArgsPredictor func(*Context, complete.Args) []string
 	Validator     func(*Context, map[string]string) error
 	Destination   *StringMap
@@ -764,123 +499,19 @@ func lookupStringMap(name string, f *flag.Flag) map[string]string {
 }
 
 // Uint64Flag is a flag with type uint64
-type Uint64Flag struct {
-	Name          string
-	Aliases       []string
-	Usage         string
-	EnvVars       []string
-	Hidden        bool
-	DefaultValue  uint64
-	DefaultText   string
-	Required      bool
-	ArgsPredictor func(*Context, complete.Args) []string
-	Validator     func(*Context, uint64) error
-	Destination   *uint64
-}
-
-// String returns a readable representation of this value
-// (for usage defaults)
-func (f *Uint64Flag) String() string {
-	return FlagStringer(f)
-}
-
-func (f *Uint64Flag) PredictArgs(c *Context, a complete.Args) []string {
-	if f.ArgsPredictor != nil {
-		return f.ArgsPredictor(c, a)
-	}
-	return []string{}
-}
-
-func (f *Uint64Flag) Validate(c *Context) error {
-	if f.Validator != nil {
-		return f.Validator(c, c.Uint64(f.Name))
-	}
-	return nil
-}
-
-// Names returns the names of the flag
-func (f *Uint64Flag) Names() []string {
-	return flagNames(f)
-}
+type Uint64Flag = TypedFlag[uint64]
 
 // Uint64 looks up the value of a local Uint64Flag, returns
 // 0 if not found
 func (c *Context) Uint64(name string) uint64 {
-	if f := lookupRawFlag(name, c); f != nil {
-		return lookupUint64(name, f)
-	}
-	return 0
-}
-
-func lookupUint64(name string, f *flag.Flag) uint64 {
-	if f == nil {
-		return 0
-	}
-
-	if parsed, err := strconv.ParseUint(f.Value.String(), 0, 64); err == nil {
-		return parsed
-	}
-
-	return 0
+	return lookupFlagValue[uint64](c, name)
 }
 
 // UintFlag is a flag with type uint
-type UintFlag struct {
-	Name          string
-	Aliases       []string
-	Usage         string
-	EnvVars       []string
-	Hidden        bool
-	DefaultValue  uint
-	DefaultText   string
-	Required      bool
-	ArgsPredictor func(*Context, complete.Args) []string
-	Validator     func(*Context, uint) error
-	Destination   *uint
-}
-
-// String returns a readable representation of this value
-// (for usage defaults)
-func (f *UintFlag) String() string {
-	return FlagStringer(f)
-}
-
-func (f *UintFlag) PredictArgs(c *Context, a complete.Args) []string {
-	if f.ArgsPredictor != nil {
-		return f.ArgsPredictor(c, a)
-	}
-	return []string{}
-}
-
-func (f *UintFlag) Validate(c *Context) error {
-	if f.Validator != nil {
-		return f.Validator(c, c.Uint(f.Name))
-	}
-	return nil
-}
-
-// Names returns the names of the flag
-func (f *UintFlag) Names() []string {
-	return flagNames(f)
-}
+type UintFlag = TypedFlag[uint]
 
 // Uint looks up the value of a local UintFlag, returns
 // 0 if not found
 func (c *Context) Uint(name string) uint {
-	if f := lookupRawFlag(name, c); f != nil {
-		return lookupUint(name, f)
-	}
-	return 0
-}
-
-func lookupUint(name string, f *flag.Flag) uint {
-	if f == nil {
-		return 0
-	}
-
-	if parsed, err := strconv.ParseUint(f.Value.String(), 0, 64); err == nil {
-		return uint(parsed)
-	}
-
-	return 0
+	return lookupFlagValue[uint](c, name)
 }
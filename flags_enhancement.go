@@ -24,7 +24,6 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"reflect"
 	"strings"
 
 	"github.com/mitchellh/go-homedir"
@@ -58,44 +57,59 @@ func (mode FlagParsingMode) IsPrefix() bool {
 	return mode != FlagParsingNormal
 }
 
-func (app *Application) parseArgs(arguments []string) (*flag.FlagSet, error) {
-	fs, err := parseArgs(app.fixArgs(arguments), flagSet(app.Name, app.Flags))
+func (app *Application) parseArgs(arguments []string) (*flag.FlagSet, flagSources, error) {
+	sources := flagSources{}
+	arguments = app.fixArgs(arguments)
+	app.sensitive.registerArgs(app.Flags, arguments)
+	fs, err := parseArgs(arguments, flagSet(app.Name, app.Flags))
 	if err != nil {
-		return fs, errors.WithStack(err)
+		if !app.DisableFlagAbbreviations {
+			err = ambiguousFlagError(app.Flags, err)
+		}
+		return fs, sources, errors.WithStack(err)
 	}
 
-	parseFlagsFromEnv(app.FlagEnvPrefix, app.Flags, fs)
+	parseFlagsFromEnv(app.FlagEnvPrefix, app.Flags, fs, sources)
+	if err := app.applyConfig(fs, app.Flags, sources); err != nil {
+		return fs, sources, err
+	}
+	app.sensitive.registerFlagValues(app.Flags, fs)
 
 	// We expand "~" for each provided string flag
 	fs.Visit(expandHomeInFlagsValues)
 
 	err = errors.WithStack(checkRequiredFlags(app.Flags, fs))
 
-	return fs, err
+	return fs, sources, err
 }
 
 func (app *Application) fixArgs(args []string) []string {
-	return fixArgs(args, app.Flags, app.Commands, FlagParsingNormal, "")
+	return fixArgs(args, app.Flags, app.allCommands(), FlagParsingNormal, "", !app.DisableFlagAbbreviations)
 }
 
-func (c *Command) parseArgs(arguments []string, prefixes []string) (*flag.FlagSet, error) {
-	fs, err := parseArgs(c.fixArgs(arguments), flagSet(c.Name, c.Flags))
+func (c *Command) parseArgs(arguments []string, prefixes []string) (*flag.FlagSet, flagSources, error) {
+	sources := flagSources{}
+	flags := c.definedFlags()
+	arguments = c.fixArgs(arguments)
+	fs, err := parseArgs(arguments, flagSet(c.Name, flags))
 	if err != nil {
-		return fs, errors.WithStack(err)
+		if !c.strictFlags {
+			err = ambiguousFlagError(flags, err)
+		}
+		return fs, sources, errors.WithStack(err)
 	}
 
-	parseFlagsFromEnv(prefixes, c.Flags, fs)
+	parseFlagsFromEnv(prefixes, flags, fs, sources)
 
 	// We expand "~" for each provided string flag
 	fs.Visit(expandHomeInFlagsValues)
 
-	err = errors.WithStack(checkRequiredFlags(c.Flags, fs))
-
-	return fs, err
+	// required flags are checked once the user had a chance to interact
+	return fs, sources, nil
 }
 
 func (c *Command) fixArgs(args []string) []string {
-	return fixArgs(args, c.Flags, nil, c.FlagParsing, "--")
+	return fixArgs(args, c.definedFlags(), nil, c.FlagParsing, "--", !c.strictFlags)
 }
 
 func parseArgs(arguments []string, fs *flag.FlagSet) (*flag.FlagSet, error) {
@@ -118,7 +132,7 @@ func parseArgs(arguments []string, fs *flag.FlagSet) (*flag.FlagSet, error) {
 	return fs, err
 }
 
-func parseFlagsFromEnv(prefixes []string, flags []Flag, fs *flag.FlagSet) {
+func parseFlagsFromEnv(prefixes []string, flags []Flag, fs *flag.FlagSet, sources flagSources) {
 	definedFlags := make(map[string]bool)
 	fs.Visit(func(f *flag.Flag) {
 		definedFlags[f.Name] = true
@@ -152,8 +166,12 @@ func parseFlagsFromEnv(prefixes []string, flags []Flag, fs *flag.FlagSet) {
 
 			terminal.Logger.Trace().Msgf("Using %s from ENV for '%s' configuration entry.\n", name, fName)
 			if err := fs.Set(fName, val); err != nil {
+				if flagIsSensitive(f) {
+					val = redactedValue
+				}
 				panic(errors.Errorf("Failed to set flag %s with value %s", fName, val))
 			}
+			sources[fName] = FlagSource{Kind: FlagSourceEnv, Name: name}
 		}
 	}
 }
@@ -170,10 +188,11 @@ func parseFlagsFromEnv(prefixes []string, flags []Flag, fs *flag.FlagSet) {
 // and not try to "fix" arguments belonging to a possible embedded command as run.
 // For this purpose, you have three different FlagParsing modes available.
 // See FlagParsingMode for more information.
-func fixArgs(args []string, flagDefs []Flag, cmdDefs []*Command, defaultMode FlagParsingMode, defaultCommand string) []string {
+func fixArgs(args []string, flagDefs []Flag, cmdDefs []*Command, defaultMode FlagParsingMode, defaultCommand string, abbreviate bool) []string {
 	var (
-		flags    = make([]string, 0)
-		nonFlags = make([]string, 0)
+		flags        = make([]string, 0)
+		nonFlags     = make([]string, 0)
+		commandFlags []Flag
 
 		command                = defaultCommand
 		parsingMode            = defaultMode
@@ -242,7 +261,26 @@ func fixArgs(args []string, flagDefs []Flag, cmdDefs []*Command, defaultMode Fla
 
 		// argument is a flag
 		if isFlag(arg) {
+			if expanded, needsValue := expandShortFlagsCluster(flagDefs, arg); expanded != nil {
+				previousFlagNeedsValue = needsValue
+				flags = append(flags, expanded...)
+				continue
+			}
+
 			cleanedFlag := cleanFlag(arg)
+			if abbreviate && strings.HasPrefix(arg, "--") && findFlag(flagDefs, cleanedFlag) == nil {
+				// abbreviations of the flags of the command are left to it
+				if _, candidates := resolveFlagPrefix(commandFlags, cleanedFlag); len(candidates) == 0 {
+					if name, _ := resolveFlagPrefix(flagDefs, cleanedFlag); name != "" {
+						value := ""
+						if index := strings.Index(arg, "="); index != -1 {
+							value = arg[index:]
+						}
+						arg = "--" + name + value
+						cleanedFlag = cleanFlag(arg)
+					}
+				}
+			}
 
 			previousFlagNeedsValue = false
 			// and is present in our flags/shortcuts
@@ -261,10 +299,7 @@ func fixArgs(args []string, flagDefs []Flag, cmdDefs []*Command, defaultMode Fla
 				// no equals sign ...
 				if equalPos == -1 {
 					// ... and not a boolean flag nor a verbosity one
-					_, isBoolFlag := flag.(*BoolFlag)
-					_, isVerbosityFlag := flag.(*verbosityFlag)
-
-					if !isBoolFlag && !isVerbosityFlag {
+					if flagTakesValue(flag) {
 						// we keep information about the previousFlag.
 						previousFlagNeedsValue = true
 					}
@@ -295,7 +330,11 @@ func fixArgs(args []string, flagDefs []Flag, cmdDefs []*Command, defaultMode Fla
 		// let's find the command if none is set yet
 		if command == "" {
 			if cmd := findCommand(arg); cmd != nil {
+				// lazy commands must be loaded to know how to parse their
+				// flags, errors are reported when the command is run
+				_ = cmd.load()
 				command = arg
+				commandFlags = cmd.definedFlags()
 				previousFlagNeedsValue = false
 				parsingMode = cmd.FlagParsing
 				continue
@@ -326,23 +365,202 @@ func findFlag(flagDefs []Flag, name string) Flag {
 			}
 		}
 	}
+	for _, f := range flagDefs {
+		for _, n := range append(negatedNames(f), fileFlagNames(f)...) {
+			if n == name {
+				return f
+			}
+		}
+	}
 	return nil
 }
 
+// resolveFlagPrefix returns the name of the only flag starting with prefix,
+// or the names of all the matching ones when there are several
+func resolveFlagPrefix(flagDefs []Flag, prefix string) (string, []string) {
+	if prefix == "" {
+		return "", nil
+	}
+	names := []string{}
+	resolved := make(map[string]bool)
+	for _, f := range flagDefs {
+		fileNames := make(map[string]bool)
+		for _, name := range fileFlagNames(f) {
+			fileNames[name] = true
+		}
+		for _, name := range append(append(f.Names(), negatedNames(f)...), fileFlagNames(f)...) {
+			if len(name) < 2 || !strings.HasPrefix(name, prefix) {
+				continue
+			}
+			// the flag is preferred over its --<name>-file companion
+			if fileNames[name] && strings.HasPrefix(strings.TrimSuffix(name, fileFlagSuffix), prefix) {
+				continue
+			}
+			// aliases of the same flag are not ambiguous
+			key := expandShortcut(flagDefs, name)
+			if _, isVerbosityFlag := f.(*verbosityFlag); isVerbosityFlag {
+				key = flagName(f)
+			}
+			if resolved[key] {
+				continue
+			}
+			resolved[key] = true
+			names = append(names, name)
+		}
+	}
+	if len(names) == 1 {
+		return names[0], names
+	}
+	return "", names
+}
+
+// ambiguousFlagError explains parsing errors caused by ambiguous
+// abbreviations of flags
+func ambiguousFlagError(flagDefs []Flag, err error) error {
+	const undefined = "flag provided but not defined: -"
+	msg := errors.Cause(err).Error()
+	if !strings.HasPrefix(msg, undefined) {
+		return err
+	}
+	name := strings.TrimLeft(strings.TrimPrefix(msg, undefined), "-")
+	if _, candidates := resolveFlagPrefix(flagDefs, name); len(candidates) > 1 {
+		for i, candidate := range candidates {
+			candidates[i] = "--" + candidate
+		}
+		return errors.Errorf(`flag "--%s" is ambiguous, it matches %s`, name, quoteNames(candidates))
+	}
+	return err
+}
+
 func expandShortcut(flagDefs []Flag, name string) string {
 	if f := findFlag(flagDefs, name); f != nil {
 		if _, isVerbosity := f.(*verbosityFlag); isVerbosity {
 			return name
 		}
+		for _, n := range negatedNames(f) {
+			if n == name {
+				return negatedFlagPrefix + flagName(f)
+			}
+		}
+		for _, n := range fileFlagNames(f) {
+			if n == name {
+				return flagName(f) + fileFlagSuffix
+			}
+		}
 
 		return flagName(f)
 	}
 	return name
 }
 
+// flagTakesValue reports whether the flag expects a value, as opposed to
+// boolean-like flags
+func flagTakesValue(f Flag) bool {
+	switch f.(type) {
+	case *BoolFlag, *verbosityFlag, *CountFlag, *quietFlag:
+		return false
+	}
+	if bf, ok := f.(interface{ isBoolFlag() bool }); ok && bf.isBoolFlag() {
+		return false
+	}
+	return true
+}
+
+// expandShortFlagsCluster expands POSIX clusters of single-letter flags:
+// "-xf" to "-x -f" and "-ofile.txt" or "-xo=file.txt" to "-x -o=file.txt".
+// Arguments matching a flag name are never considered as clusters. The
+// returned boolean is true when the last flag expects the next argument as
+// its value.
+func expandShortFlagsCluster(flagDefs []Flag, arg string) ([]string, bool) {
+	if len(arg) < 3 || arg[0] != '-' || arg[1] == '-' {
+		return nil, false
+	}
+	cluster := arg[1:]
+	name := cluster
+	if index := strings.Index(name, "="); index != -1 {
+		name = name[:index]
+	}
+	if findFlag(flagDefs, name) != nil {
+		return nil, false
+	}
+
+	expanded := []string{}
+	for i := 0; i < len(cluster); {
+		f := findFlag(flagDefs, cluster[i:i+1])
+		if f == nil {
+			return nil, false
+		}
+		if _, isVerbosityFlag := f.(*verbosityFlag); isVerbosityFlag {
+			// repetitions of the verbosity shortcut are flags on their own
+			j := i + 1
+			for j < len(cluster) && cluster[j] == cluster[i] && findFlag(flagDefs, cluster[i:j+1]) != nil {
+				j++
+			}
+			expanded = append(expanded, "-"+cluster[i:j])
+			i = j
+			continue
+		}
+		if !flagTakesValue(f) {
+			expanded = append(expanded, "--"+flagName(f))
+			i++
+			continue
+		}
+		// the rest of the cluster is the value
+		if i+1 == len(cluster) {
+			return append(expanded, "--"+flagName(f)), true
+		}
+		return append(expanded, "--"+flagName(f)+"="+strings.TrimPrefix(cluster[i+1:], "=")), false
+	}
+	return expanded, false
+}
+
+// checkShortFlagsClusters panics when a multi-letter flag name could also be
+// read as a cluster of single-letter flags
+func checkShortFlagsClusters(flags []Flag, commandName string) {
+	for _, f := range flags {
+		if _, isVerbosityFlag := f.(*verbosityFlag); isVerbosityFlag {
+			continue
+		}
+		for _, name := range append(f.Names(), negatedNames(f)...) {
+			if len(name) < 2 || !isShortFlagsCluster(flags, f, name) {
+				continue
+			}
+			msg := fmt.Sprintf("flag %s is ambiguous: -%s is also a cluster of short flags", name, name)
+			if commandName != "" {
+				msg = fmt.Sprintf("flag %s of command %s is ambiguous: -%s is also a cluster of short flags", name, commandName, name)
+			}
+			panic(msg)
+		}
+	}
+}
+
+// isShortFlagsCluster reports whether all letters of the name of owner are
+// single-letter flags, only the last one being allowed to take a value. Names
+// only made of the short name of their own flag, like "xxx" for "x", are
+// not considered as clusters.
+func isShortFlagsCluster(flags []Flag, owner Flag, name string) bool {
+	others := false
+	for i := range name {
+		f := findFlag(flags, name[i:i+1])
+		if f == nil || (flagTakesValue(f) && i != len(name)-1) {
+			return false
+		}
+		others = others || f != owner
+	}
+	return others
+}
+
 func expandHomeInFlagsValues(f *flag.Flag) {
 	// This is the safest right now
-	if reflect.ValueOf(f.Value).Elem().Kind() != reflect.String {
+	getter, ok := f.Value.(flag.Getter)
+	if !ok {
+		return
+	}
+	if _, isString := getter.Get().(string); !isString {
+		return
+	}
+	// values read from files or stdin are used as is
+	if v, ok := f.Value.(fileValue); ok && v.valueReadFromFile() {
 		return
 	}
 	val := ExpandHome(f.Value.String())
@@ -23,7 +23,12 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/mitchellh/go-homedir"
 	"github.com/symfony-cli/terminal"
 	. "gopkg.in/check.v1"
 )
@@ -78,7 +83,7 @@ func (ts *CliEnhancementSuite) TestFixAndParseArgsApplication(c *C) {
 	argsExpected = []string{"upload", "-reference=4", "file1", "file2"}
 	sorted = testApp.fixArgs(args)
 	c.Assert(sorted, DeepEquals, expected)
-	fs, err := testApp.parseArgs(args)
+	fs, _, err := testApp.parseArgs(args)
 	c.Assert(err, IsNil)
 	ctx = NewContext(&testApp, fs, nil)
 	c.Check(ctx.Int("v"), Equals, 3)
@@ -90,7 +95,7 @@ func (ts *CliEnhancementSuite) TestFixAndParseArgsApplication(c *C) {
 	argsExpected = []string{"upload", "-reference", "4", "file1", "file2"}
 	sorted = testApp.fixArgs(args)
 	c.Assert(sorted, DeepEquals, expected)
-	fs, _ = testApp.parseArgs(args)
+	fs, _, _ = testApp.parseArgs(args)
 	ctx = NewContext(&testApp, fs, nil)
 	c.Check(ctx.Int("v"), Equals, 3)
 	c.Check(ctx.Bool("quiet"), Equals, true)
@@ -101,7 +106,7 @@ func (ts *CliEnhancementSuite) TestFixAndParseArgsApplication(c *C) {
 	argsExpected = []string{"upload", "-reference=4", "file1", "file2"}
 	sorted = testApp.fixArgs(args)
 	c.Assert(sorted, DeepEquals, expected)
-	fs, _ = testApp.parseArgs(args)
+	fs, _, _ = testApp.parseArgs(args)
 	ctx = NewContext(&testApp, fs, nil)
 	c.Check(ctx.Int("v"), Equals, 3)
 	c.Check(ctx.Bool("quiet"), Equals, true)
@@ -112,7 +117,7 @@ func (ts *CliEnhancementSuite) TestFixAndParseArgsApplication(c *C) {
 	argsExpected = []string{"upload", "-reference=4", "upload", "file1", "file2"}
 	sorted = testApp.fixArgs(args)
 	c.Assert(sorted, DeepEquals, expected)
-	fs, _ = testApp.parseArgs(args)
+	fs, _, _ = testApp.parseArgs(args)
 	ctx = NewContext(&testApp, fs, nil)
 	c.Check(ctx.Int("v"), Equals, 3)
 	c.Check(ctx.Bool("quiet"), Equals, true)
@@ -123,7 +128,7 @@ func (ts *CliEnhancementSuite) TestFixAndParseArgsApplication(c *C) {
 	argsExpected = []string{"curl", "-reference=4", "-X", "POST", "http://blackfire.io"}
 	sorted = testApp.fixArgs(args)
 	c.Assert(sorted, DeepEquals, expected)
-	fs, _ = testApp.parseArgs(args)
+	fs, _, _ = testApp.parseArgs(args)
 	ctx = NewContext(&testApp, fs, nil)
 	c.Check(ctx.Int("v"), Equals, 3)
 	c.Check(ctx.Bool("quiet"), Equals, true)
@@ -134,7 +139,7 @@ func (ts *CliEnhancementSuite) TestFixAndParseArgsApplication(c *C) {
 	argsExpected = []string{"curl"}
 	sorted = testApp.fixArgs(args)
 	c.Assert(sorted, DeepEquals, expected)
-	fs, _ = testApp.parseArgs(args)
+	fs, _, _ = testApp.parseArgs(args)
 	ctx = NewContext(&testApp, fs, nil)
 	c.Check(ctx.Int("v"), Equals, 1)
 	c.Check(ctx.Bool("quiet"), Equals, false)
@@ -145,7 +150,7 @@ func (ts *CliEnhancementSuite) TestFixAndParseArgsApplication(c *C) {
 	argsExpected = []string{"agent"}
 	sorted = testApp.fixArgs(args)
 	c.Assert(sorted, DeepEquals, expected)
-	fs, _ = testApp.parseArgs(args)
+	fs, _, _ = testApp.parseArgs(args)
 	ctx = NewContext(&testApp, fs, nil)
 	c.Check(ctx.Int("v"), Equals, 1)
 	c.Check(ctx.Bool("quiet"), Equals, false)
@@ -156,7 +161,7 @@ func (ts *CliEnhancementSuite) TestFixAndParseArgsApplication(c *C) {
 	argsExpected = []string{"agent"}
 	sorted = testApp.fixArgs(args)
 	c.Assert(sorted, DeepEquals, expected)
-	fs, _ = testApp.parseArgs(args)
+	fs, _, _ = testApp.parseArgs(args)
 	ctx = NewContext(&testApp, fs, nil)
 	c.Check(ctx.Int("v"), Equals, 4)
 	c.Check(ctx.Bool("quiet"), Equals, false)
@@ -167,7 +172,7 @@ func (ts *CliEnhancementSuite) TestFixAndParseArgsApplication(c *C) {
 	argsExpected = []string{"run", "--reference", "8", "php", "vd.php"}
 	sorted = testApp.fixArgs(args)
 	c.Check(sorted, DeepEquals, expected)
-	fs, _ = testApp.parseArgs(args)
+	fs, _, _ = testApp.parseArgs(args)
 	ctx = NewContext(&testApp, fs, nil)
 	c.Check(ctx.Int("v"), Equals, 4)
 	c.Check(ctx.Bool("quiet"), Equals, false)
@@ -178,7 +183,7 @@ func (ts *CliEnhancementSuite) TestFixAndParseArgsApplication(c *C) {
 	argsExpected = []string{"run", "-v=4", "--reference", "8", "php", "vd.php"}
 	sorted = testApp.fixArgs(args)
 	c.Assert(sorted, DeepEquals, expected)
-	fs, _ = testApp.parseArgs(args)
+	fs, _, _ = testApp.parseArgs(args)
 	ctx = NewContext(&testApp, fs, nil)
 	c.Check(ctx.Int("v"), Equals, 1)
 	c.Check(ctx.Bool("quiet"), Equals, false)
@@ -189,7 +194,7 @@ func (ts *CliEnhancementSuite) TestFixAndParseArgsApplication(c *C) {
 	argsExpected = []string{"foo", "--reference", "8", "php", "vd.php"}
 	sorted = testApp.fixArgs(args)
 	c.Assert(sorted, DeepEquals, expected)
-	fs, _ = testApp.parseArgs(args)
+	fs, _, _ = testApp.parseArgs(args)
 	ctx = NewContext(&testApp, fs, nil)
 	c.Check(ctx.Int("v"), Equals, 4)
 	c.Check(ctx.Bool("quiet"), Equals, false)
@@ -200,7 +205,7 @@ func (ts *CliEnhancementSuite) TestFixAndParseArgsApplication(c *C) {
 	argsExpected = []string{"upload", "-reference=19", "profiler/README.md"}
 	sorted = testApp.fixArgs(args)
 	c.Assert(sorted, DeepEquals, expected)
-	fs, _ = testApp.parseArgs(args)
+	fs, _, _ = testApp.parseArgs(args)
 	ctx = NewContext(&testApp, fs, nil)
 	c.Check(ctx.Int("v"), Equals, 1)
 	c.Check(ctx.Bool("quiet"), Equals, false)
@@ -211,7 +216,7 @@ func (ts *CliEnhancementSuite) TestFixAndParseArgsApplication(c *C) {
 	argsExpected = []string{"curl", "-reference=4", "-samples=4", "http://labomedia.org"}
 	sorted = testApp.fixArgs(args)
 	c.Assert(sorted, DeepEquals, expected)
-	fs, _ = testApp.parseArgs(args)
+	fs, _, _ = testApp.parseArgs(args)
 	ctx = NewContext(&testApp, fs, nil)
 	c.Check(ctx.Int("v"), Equals, 4)
 	c.Check(ctx.Bool("quiet"), Equals, false)
@@ -222,7 +227,7 @@ func (ts *CliEnhancementSuite) TestFixAndParseArgsApplication(c *C) {
 	argsExpected = []string{"run", "--reference", "8", "php", "vd.php", "--config=foo", "--foo", "bar"}
 	sorted = testApp.fixArgs(args)
 	c.Assert(sorted, DeepEquals, expected)
-	fs, _ = testApp.parseArgs(args)
+	fs, _, _ = testApp.parseArgs(args)
 	ctx = NewContext(&testApp, fs, nil)
 	c.Check(ctx.Int("v"), Equals, 4)
 	c.Check(ctx.Bool("quiet"), Equals, false)
@@ -269,14 +274,14 @@ func (ts *CliEnhancementSuite) TestFixAndParseArgsApplicationVerbosityFlag(c *C)
 		expected := []string{tt.arg, "envs", "-p", "agb6vnth4arfo"}
 		sorted := testApp.fixArgs(args)
 		c.Assert(sorted, DeepEquals, expected)
-		fs, _ := testApp.parseArgs(args)
+		fs, _, _ := testApp.parseArgs(args)
 		ctx := NewContext(&testApp, fs, nil)
 
 		c.Check(terminal.GetLogLevel(), Equals, tt.expectedLevel)
 		c.Check(ctx.IsSet("log-level"), Equals, true)
 
 		cmd := testApp.Command(ctx.Args().first())
-		fs, _ = cmd.parseArgs(ctx.Args().Tail(), []string{})
+		fs, _, _ = cmd.parseArgs(ctx.Args().Tail(), []string{})
 		ctx = NewContext(&testApp, fs, nil)
 
 		c.Check(ctx.String("project"), Equals, "agb6vnth4arfo")
@@ -297,7 +302,7 @@ func (ts *CliEnhancementSuite) TestFixAndParseArgsCommand(c *C) {
 	expected = []string{"-reference=4", "--samples=10", "-test", "-samples", "5", "-H='Host: foo'", "--", "file1", "foo"}
 	sorted = curlCmd.fixArgs(args)
 	c.Assert(sorted, DeepEquals, expected)
-	fs, _ = curlCmd.parseArgs(args, []string{})
+	fs, _, _ = curlCmd.parseArgs(args, []string{})
 	ctx = NewContext(&testApp, fs, nil)
 	c.Check(ctx.Int("reference"), Equals, 4)
 	c.Check(ctx.Int("samples"), Equals, 5)
@@ -307,7 +312,7 @@ func (ts *CliEnhancementSuite) TestFixAndParseArgsCommand(c *C) {
 	expected = []string{"-reference=4", "--samples=10", "-test", "-samples", "5", "-H='Host: foo'", "--", "file1", "foo"}
 	sorted = uploadCmd.fixArgs(args)
 	c.Assert(sorted, DeepEquals, expected)
-	fs, _ = uploadCmd.parseArgs(args, []string{})
+	fs, _, _ = uploadCmd.parseArgs(args, []string{})
 	ctx = NewContext(&testApp, fs, nil)
 	c.Check(ctx.Int("reference"), Equals, 4)
 	c.Check(ctx.Int("samples"), Equals, 5)
@@ -317,7 +322,7 @@ func (ts *CliEnhancementSuite) TestFixAndParseArgsCommand(c *C) {
 	expected = append([]string{"--"}, args...)
 	sorted = fooCmd.fixArgs(args)
 	c.Assert(sorted, DeepEquals, expected)
-	fs, _ = fooCmd.parseArgs(args, []string{})
+	fs, _, _ = fooCmd.parseArgs(args, []string{})
 	ctx = NewContext(&testApp, fs, nil)
 	c.Check(ctx.Int("reference"), Equals, 0)
 	c.Check(ctx.Int("samples"), Equals, 0)
@@ -327,7 +332,7 @@ func (ts *CliEnhancementSuite) TestFixAndParseArgsCommand(c *C) {
 	expected = []string{"-reference=4", "--samples=10", "-test", "--", "file1", "-s=", "5", "-H='Host: foo'", "foo"}
 	sorted = runCmd.fixArgs(args)
 	c.Assert(sorted, DeepEquals, expected)
-	fs, _ = runCmd.parseArgs(args, []string{})
+	fs, _, _ = runCmd.parseArgs(args, []string{})
 	ctx = NewContext(&testApp, fs, nil)
 	c.Check(ctx.Int("reference"), Equals, 4)
 	c.Check(ctx.Int("samples"), Equals, 10)
@@ -338,7 +343,7 @@ func (ts *CliEnhancementSuite) TestFixAndParseArgsCommand(c *C) {
 	expected = []string{"-reference=4", "-samples", "5", "--", "--samples=10", "file1", "-f=", "3", "foo"}
 	sorted = curlCmd.fixArgs(dashDashArgs)
 	c.Assert(sorted, DeepEquals, expected)
-	fs, _ = curlCmd.parseArgs(dashDashArgs, []string{})
+	fs, _, _ = curlCmd.parseArgs(dashDashArgs, []string{})
 	ctx = NewContext(&testApp, fs, nil)
 	c.Check(ctx.Int("reference"), Equals, 4)
 	c.Check(ctx.Int("samples"), Equals, 5)
@@ -348,7 +353,7 @@ func (ts *CliEnhancementSuite) TestFixAndParseArgsCommand(c *C) {
 	expected = []string{"-reference=4", "--unknown", "-reference", "-samples", "5", "--samples=10", "-f=", "3", "--", "file1", "foo"}
 	sorted = curlCmd.fixArgs(weirdArgs)
 	c.Assert(sorted, DeepEquals, expected)
-	fs, err = curlCmd.parseArgs(weirdArgs, []string{})
+	fs, _, err = curlCmd.parseArgs(weirdArgs, []string{})
 	c.Check(err, Not(IsNil))
 	ctx = NewContext(&testApp, fs, nil)
 	c.Check(ctx.Int("reference"), Equals, 4)
@@ -465,3 +470,117 @@ func (ts *CliEnhancementSuite) TestFlagsValidation(c *C) {
 	c.Assert(app.Run([]string{"app", "test", "--sub-bar=bar"}), IsNil)
 	c.Assert(app.Run([]string{"app", "test", "--sub-bar=toto"}), ErrorMatches, ".*invalid value for flag \"sub-bar\".*")
 }
+
+func (ts *CliEnhancementSuite) TestExpandHomeInFlagsValues(c *C) {
+	home, err := homedir.Dir()
+	c.Assert(err, IsNil)
+
+	flags := []Flag{
+		&StringFlag{Name: "path"},
+		&IntFlag{Name: "count"},
+	}
+	set := flag.NewFlagSet("test", 0)
+	for _, f := range flags {
+		f.Apply(set)
+	}
+	c.Assert(set.Parse([]string{"--path", "~/foo", "--count", "1"}), IsNil)
+	set.Visit(expandHomeInFlagsValues)
+
+	c.Assert(set.Lookup("path").Value.String(), Equals, filepath.Join(home, "foo"))
+	c.Assert(set.Lookup("count").Value.String(), Equals, "1")
+}
+
+func (ts *CliEnhancementSuite) TestFixArgsShortFlagsClusters(c *C) {
+	tarCmd := &Command{
+		Name: "tar",
+		Flags: []Flag{
+			&BoolFlag{Name: "extract", Aliases: []string{"x"}},
+			&BoolFlag{Name: "gzip", Aliases: []string{"z"}},
+			&StringFlag{Name: "file", Aliases: []string{"f"}},
+			&CountFlag{Name: "debug", Aliases: []string{"d"}},
+			&StringFlag{Name: "xo"},
+		},
+	}
+
+	for args, expected := range map[string][]string{
+		"-xzf archive.tgz":    {"--extract", "--gzip", "--file", "archive.tgz", "--"},
+		"-xfarchive.tgz":      {"--extract", "--file=archive.tgz", "--"},
+		"-zf=archive.tgz foo": {"--gzip", "--file=archive.tgz", "--", "foo"},
+		"-ffoo -ddx":          {"--file=foo", "--debug", "--debug", "--extract", "--"},
+		"-xo bar":             {"-xo", "bar", "--"},
+		"-xq":                 {"-xq", "--"},
+	} {
+		c.Check(tarCmd.fixArgs(strings.Fields(args)), DeepEquals, expected, Commentf(args))
+	}
+
+	flags := []Flag{LogLevelFlag, &BoolFlag{Name: "quiet", Aliases: []string{"q"}}}
+	c.Assert(fixArgs([]string{"-qvv", "list"}, flags, nil, FlagParsingNormal, "", true), DeepEquals, []string{"--quiet", "-vv", "list"})
+}
+
+func (ts *CliEnhancementSuite) TestShortFlagsClustersAmbiguity(c *C) {
+	app := &Application{
+		Commands: []*Command{
+			{
+				Name: "tar",
+				Flags: []Flag{
+					&BoolFlag{Name: "extract", Aliases: []string{"x"}},
+					&StringFlag{Name: "file", Aliases: []string{"f"}},
+					&BoolFlag{Name: "xf"},
+				},
+			},
+		},
+	}
+	c.Assert(app.setup, PanicMatches, `flag xf of command tar is ambiguous: -xf is also a cluster of short flags`)
+}
+
+func (ts *CliEnhancementSuite) TestFlagsAbbreviations(c *C) {
+	var (
+		region  string
+		retries int
+		force   bool
+		token   string
+	)
+	newApp := func(strict bool) *Application {
+		return &Application{
+			Writer:                   io.Discard,
+			DisableFlagAbbreviations: strict,
+			Flags:                    []Flag{&StringFlag{Name: "region"}},
+			Commands: []*Command{
+				{
+					Name: "deploy",
+					Flags: []Flag{
+						&IntFlag{Name: "retries"},
+						&StringFlag{Name: "token", FromFile: true},
+						&BoolFlag{Name: "force"},
+						&BoolFlag{Name: "format"},
+					},
+					Action: func(ctx *Context) error {
+						region, retries, force, token = ctx.String("region"), ctx.Int("retries"), ctx.Bool("force"), ctx.String("token")
+						return nil
+					},
+				},
+			},
+		}
+	}
+
+	_, err := newApp(false).Execute([]string{"app", "deploy", "--ret=3", "--reg", "eu", "--forc"})
+	c.Assert(err, IsNil)
+	c.Assert(region, Equals, "eu")
+	c.Assert(retries, Equals, 3)
+	c.Assert(force, Equals, true)
+
+	_, err = newApp(false).Execute([]string{"app", "deploy", "--fo"})
+	c.Assert(err, ErrorMatches, `(?s).*flag "--fo" is ambiguous, it matches "--force" and "--format".*`)
+	// the flag is preferred over its --token-file companion
+	_, err = newApp(false).Execute([]string{"app", "deploy", "--tok=abc"})
+	c.Assert(err, IsNil)
+	c.Assert(token, Equals, "abc")
+	tokenFile := filepath.Join(c.MkDir(), "token")
+	c.Assert(os.WriteFile(tokenFile, []byte("s3cr3t\n"), 0600), IsNil)
+	_, err = newApp(false).Execute([]string{"app", "deploy", "--token-f", tokenFile})
+	c.Assert(err, IsNil)
+	c.Assert(token, Equals, "s3cr3t")
+
+	_, err = newApp(true).Execute([]string{"app", "deploy", "--ret=3"})
+	c.Assert(err, ErrorMatches, `(?s).*flag provided but not defined: -ret.*`)
+}
@@ -32,9 +32,17 @@ type BeforeFunc func(*Context) error
 // subcommand has finished it is run even if Action() panics
 type AfterFunc func(*Context) error
 
+// InteractFunc is executed before the command input is validated when it is
+// interactive, it can ask the user for missing arguments and flags
+type InteractFunc func(*Context) error
+
 // ActionFunc is the action to execute when no subcommands are specified
 type ActionFunc func(*Context) error
 
+// MiddlewareFunc wraps the action of a command, the wrapped action is
+// expected to call next
+type MiddlewareFunc func(next ActionFunc) ActionFunc
+
 // CommandNotFoundFunc is executed if the proper command cannot be found
 type CommandNotFoundFunc func(*Context, string) error
 
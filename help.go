@@ -33,7 +33,8 @@ import (
 
 // AppHelpTemplate is the text template for the Default help topic.
 // cli.go uses text/template to render templates. You can
-// render custom help text by setting this variable.
+// render custom help text by setting this variable or
+// Application.AppHelpTemplate.
 var AppHelpTemplate = `<info>{{.Name}}</>{{if .Version}} version <comment>{{.Version}}</>{{end}}{{if .Copyright}} {{.Copyright}}{{end}}
 {{.Usage}}
 
@@ -53,7 +54,8 @@ var AppHelpTemplate = `<info>{{.Name}}</>{{if .Version}} version <comment>{{.Ver
 
 // CategoryHelpTemplate is the text template for the category help topic.
 // cli.go uses text/template to render templates. You can
-// render custom help text by setting this variable.
+// render custom help text by setting this variable or
+// Application.CategoryHelpTemplate.
 var CategoryHelpTemplate = `{{with .App }}<info>{{.Name}}</>{{if .Version}} version <comment>{{.Version}}</>{{end}}{{if .Copyright}} {{.Copyright}}{{end}}
 {{.Usage}}
 
@@ -72,7 +74,8 @@ var CategoryHelpTemplate = `{{with .App }}<info>{{.Name}}</>{{if .Version}} vers
 
 // CommandHelpTemplate is the text template for the command help topic.
 // cli.go uses text/template to render templates. You can
-// render custom help text by setting this variable.
+// render custom help text by setting this variable or
+// Application.CommandHelpTemplate.
 var CommandHelpTemplate = `{{if .Usage}}<comment>Description:</>
   {{.Usage}}
 
@@ -85,6 +88,14 @@ var CommandHelpTemplate = `{{if .Usage}}<comment>Description:</>
 
 <comment>Options:</>
   {{range .VisibleFlags}}{{.}}
+  {{end}}{{end}}{{if .VisibleFlagGroups}}
+
+<comment>Option constraints:</>
+  {{range .VisibleFlagGroups}}{{.}}
+  {{end}}{{end}}{{if .VisibleSubcommands}}
+
+<comment>Available commands:</>
+  {{range .VisibleSubcommands}}<info>{{join .Names ", "}}</>{{"\t"}}{{.Usage}}
   {{end}}{{end}}{{if .Description}}
 
 <comment>Help:</>
@@ -121,11 +132,48 @@ type helpPrinter func(w io.Writer, templ string, data interface{})
 // HelpPrinter is a function that writes the help output. If not set a default
 // is used. The function signature is:
 // func(w io.Writer, templ string, data interface{})
+// It can be overridden per application with Application.HelpPrinter.
 var HelpPrinter helpPrinter = printHelp
 
-// VersionPrinter prints the version for the App
+// VersionPrinter prints the version for the App. It can be overridden per
+// application with Application.VersionPrinter.
 var VersionPrinter = printVersion
 
+func (a *Application) helpPrinter() helpPrinter {
+	if a.HelpPrinter != nil {
+		return a.HelpPrinter
+	}
+	return HelpPrinter
+}
+
+func (a *Application) versionPrinter() func(*Context) {
+	if a.VersionPrinter != nil {
+		return a.VersionPrinter
+	}
+	return VersionPrinter
+}
+
+func (a *Application) appHelpTemplate() string {
+	if a.AppHelpTemplate != "" {
+		return a.AppHelpTemplate
+	}
+	return AppHelpTemplate
+}
+
+func (a *Application) categoryHelpTemplate() string {
+	if a.CategoryHelpTemplate != "" {
+		return a.CategoryHelpTemplate
+	}
+	return CategoryHelpTemplate
+}
+
+func (a *Application) commandHelpTemplate() string {
+	if a.CommandHelpTemplate != "" {
+		return a.CommandHelpTemplate
+	}
+	return CommandHelpTemplate
+}
+
 // ShowAppHelpAction is an action that displays the global help or for the
 // specified command.
 func ShowAppHelpAction(c *Context) error {
@@ -136,34 +184,40 @@ func ShowAppHelpAction(c *Context) error {
 		return ShowCommandHelp(c, args.first())
 	}
 
+	// plugins are only looked for when listing all the commands
+	c.App.loadPlugins()
 	return ShowAppHelp(c)
 }
 
 // ShowAppHelp is an action that displays the help.
 func ShowAppHelp(c *Context) error {
-	HelpPrinter(c.App.Writer, AppHelpTemplate, c.App)
+	c.App.helpPrinter()(c.App.Writer, c.App.appHelpTemplate(), c.App)
 	return nil
 }
 
 // ShowCommandHelp prints help for the given command
 func ShowCommandHelp(ctx *Context, command string) error {
-	if c, _ := ctx.App.BestCommand(command); c != nil {
+	c, _ := ctx.App.BestCommand(command)
+	if c == nil && len(ctx.App.categoriesWithPrefix(command)) == 0 {
+		// the command might be a plugin
+		ctx.App.loadPlugins()
+		c, _ = ctx.App.BestCommand(command)
+	}
+	if c != nil {
+		if err := c.load(); err != nil {
+			return err
+		}
+
 		if c.DescriptionFunc != nil {
 			c.Description = c.DescriptionFunc(c, ctx.App)
 		}
 
-		HelpPrinter(ctx.App.Writer, CommandHelpTemplate, c)
+		ctx.App.helpPrinter()(ctx.App.Writer, ctx.App.commandHelpTemplate(), c)
 		return nil
 	}
 
-	categories := []CommandCategory{}
-	for _, c := range ctx.App.VisibleCategories() {
-		if strings.HasPrefix(c.Name(), command) {
-			categories = append(categories, c)
-		}
-	}
-	if len(categories) > 0 {
-		HelpPrinter(ctx.App.Writer, CategoryHelpTemplate, struct {
+	if categories := ctx.App.categoriesWithPrefix(command); len(categories) > 0 {
+		ctx.App.helpPrinter()(ctx.App.Writer, ctx.App.categoryHelpTemplate(), struct {
 			App        *Application
 			Categories []CommandCategory
 		}{
@@ -205,13 +259,13 @@ func findAlternatives(name string, commands []*Command) []string {
 	alternatives := []string{}
 
 	for _, command := range commands {
-		if command.Category != "" {
-			if command.Category == name {
+		if namespace := command.namespace(); namespace != "" {
+			if namespace == name {
 				alternatives = append(alternatives, command.FullName())
 				continue
 			}
 
-			lev := levenshtein.Distance(name, command.Category, nil)
+			lev := levenshtein.Distance(name, namespace, nil)
 			if lev <= len(name)/3 {
 				alternatives = append(alternatives, command.FullName())
 				continue
@@ -243,11 +297,11 @@ func findAlternatives(name string, commands []*Command) []string {
 
 // ShowVersion prints the version number of the App
 func ShowVersion(c *Context) {
-	VersionPrinter(c)
+	c.App.versionPrinter()(c)
 }
 
 func printVersion(c *Context) {
-	HelpPrinter(c.App.Writer, "<info>{{.Name}}</>{{if .Version}} version <comment>{{.Version}}</>{{end}}{{if .Copyright}} {{.Copyright}}{{end}} ({{.BuildDate}} - {{.Channel}})\n", c.App)
+	c.App.helpPrinter()(c.App.Writer, "<info>{{.Name}}</>{{if .Version}} version <comment>{{.Version}}</>{{end}}{{if .Copyright}} {{.Copyright}}{{end}} ({{.BuildDate}} - {{.Channel}})\n", c.App)
 }
 
 func printHelp(out io.Writer, templ string, data interface{}) {
@@ -268,8 +322,8 @@ func printHelp(out io.Writer, templ string, data interface{}) {
 
 func checkVersion(c *Context) bool {
 	found := false
-	if VersionFlag.Name != "" {
-		for _, name := range VersionFlag.Names() {
+	if f := c.App.VersionFlag; f != nil && f.Name != "" {
+		for _, name := range f.Names() {
 			if c.Bool(name) {
 				found = true
 			}
@@ -279,11 +333,15 @@ func checkVersion(c *Context) bool {
 }
 
 func IsHelp(c *Context) bool {
-	return checkHelp(c) || c.Command == helpCommand
+	return checkHelp(c) || (c.Command != nil && c.Command == c.App.HelpCommand)
 }
 
 func checkHelp(c *Context) bool {
-	for _, name := range HelpFlag.Names() {
+	if c.App.HelpFlag == nil || c.App.HelpFlag.Name == "" {
+		return false
+	}
+
+	for _, name := range c.App.HelpFlag.Names() {
 		if c.Bool(name) {
 			return true
 		}
@@ -293,7 +351,7 @@ func checkHelp(c *Context) bool {
 }
 
 func checkCommandHelp(c *Context, name string) bool {
-	if c.Bool("h") || c.Bool("help") {
+	if checkHelp(c) {
 		_ = ShowCommandHelp(c, name)
 		return true
 	}
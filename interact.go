@@ -0,0 +1,177 @@
+/*
+ * Copyright (c) 2021-present Fabien Potencier <fabien@symfony.com>
+ *
+ * This file is part of Symfony CLI project
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package console
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/symfony-cli/terminal"
+	"golang.org/x/term"
+)
+
+// interact gives the command a chance to complete its input before it is
+// validated, then asks for the missing required arguments and flags. Nothing
+// happens when the input is not interactive (--no-interaction for instance).
+func (c *Command) interact(ctx *Context) error {
+	if !terminal.Stdin.IsInteractive() {
+		return nil
+	}
+
+	if c.Interact != nil {
+		if err := c.Interact(ctx); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range c.definedFlags() {
+		if flagIsRequired(f) && !ctx.IsSet(flagName(f)) {
+			askFlag(ctx, f)
+		}
+	}
+
+	for _, arg := range c.Args {
+		if arg.Optional {
+			break
+		}
+		if arg.Slice {
+			if len(ctx.Args().Tail()) == 0 {
+				if err := askArg(ctx, arg); err != nil {
+					return err
+				}
+			}
+			break
+		}
+		if ctx.Args().Get(arg.Name) == "" {
+			if err := askArg(ctx, arg); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func askFlag(ctx *Context, f Flag) {
+	name := flagName(f)
+	label := flagStringField(f, "Usage")
+	if label == "" {
+		label = name
+	}
+	fl := ctx.flagSet.Lookup(name)
+	if fl == nil {
+		return
+	}
+
+	if bf, ok := fl.Value.(interface{ IsBoolFlag() bool }); ok && bf.IsBoolFlag() {
+		for {
+			if err := ctx.flagSet.Set(name, fmt.Sprint(terminal.AskConfirmation(label, false))); err != nil {
+				terminal.Eprintfln("<error>%s</>", err)
+				continue
+			}
+			ctx.setFlagSource(name, FlagSourceInteractive)
+			return
+		}
+	}
+
+	ask := terminal.AskString
+	if flagIsSensitive(f) {
+		ask = askHiddenString
+	}
+	ask(fmt.Sprintf("<question>%s</> (%s%s): ", label, prefixFor(name), name), func(answer string) (string, bool) {
+		if answer == "" {
+			return answer, false
+		}
+		if cf, ok := f.(*ChoiceFlag); ok {
+			choice, err := matchChoice(cf.Choices, answer, cf.CaseInsensitive)
+			if err != nil {
+				terminal.Eprintfln("<error>%s</>", err)
+				return answer, false
+			}
+			answer = choice
+		}
+		if flagIsSensitive(f) {
+			ctx.App.sensitive.register(answer)
+		}
+		if err := ctx.flagSet.Set(name, answer); err != nil {
+			terminal.Eprintfln("<error>%s</>", err)
+			return answer, false
+		}
+		ctx.setFlagSource(name, FlagSourceInteractive)
+		return answer, true
+	})
+}
+
+// askHiddenString is like terminal.AskString but the answer is not echoed
+// when reading from a terminal
+func askHiddenString(message string, validator func(string) (string, bool)) string {
+	if !terminal.Stdin.IsInteractive() {
+		return ""
+	}
+
+	fd := int(terminal.Stdin.Fd())
+	reader := bufio.NewReader(terminal.Stdin)
+	for {
+		terminal.Print(message)
+		var answer string
+		if term.IsTerminal(fd) {
+			line, err := term.ReadPassword(fd)
+			terminal.Println()
+			if err != nil {
+				return ""
+			}
+			answer = string(line)
+		} else {
+			line, err := reader.ReadString('\n')
+			if err != nil && line == "" {
+				return ""
+			}
+			answer = strings.TrimRight(line, "\r\n")
+		}
+		if answer, isValid := validator(strings.TrimSpace(answer)); isValid {
+			return answer
+		}
+	}
+}
+
+func askArg(ctx *Context, arg *Arg) error {
+	label := arg.Description
+	if label == "" {
+		label = arg.Name
+	}
+
+	var values []string
+	terminal.AskString(fmt.Sprintf("<question>%s</> (%s): ", label, arg.Name), func(answer string) (string, bool) {
+		if !arg.Slice {
+			values = []string{answer}
+			return answer, answer != ""
+		}
+
+		var err error
+		if values, err = splitCommandLine(answer); err != nil {
+			terminal.Eprintfln("<error>%s</>", err)
+			return answer, false
+		}
+		return answer, len(values) > 0
+	})
+
+	return ctx.SetArg(arg.Name, values...)
+}
@@ -0,0 +1,234 @@
+/*
+ * Copyright (c) 2021-present Fabien Potencier <fabien@symfony.com>
+ *
+ * This file is part of Symfony CLI project
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package console
+
+import (
+	"flag"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/symfony-cli/terminal"
+)
+
+func TestCommandInteract(t *testing.T) {
+	resetOutputsInput()
+	defer resetOutputsInput()
+	terminal.Stdin.SetInteractive(true)
+	defer terminal.Stdin.SetInteractive(false)
+	// prompts read the input line by line
+	terminal.Stdin.SetReader(iotest.OneByteReader(stdin))
+	defer terminal.Stdin.SetReader(stdin)
+
+	// answers: the "env" flag, then the "files" slice argument (first answer
+	// being invalid)
+	stdin.WriteString("prod\n\n'a b' c\n")
+
+	interactCalled := false
+	app := &Application{Writer: io.Discard}
+	app.setup()
+	command := &Command{
+		Name: "deploy",
+		Flags: []Flag{
+			&StringFlag{Name: "env", Usage: "The environment", Required: true},
+			&StringFlag{Name: "tag", Required: true},
+		},
+		Args: ArgDefinition{
+			{Name: "project", Description: "The project"},
+			{Name: "files", Slice: true},
+		},
+		Interact: func(c *Context) error {
+			interactCalled = true
+			if err := c.Set("tag", "v1"); err != nil {
+				return err
+			}
+			return c.SetArg("project", "website")
+		},
+		Action: func(c *Context) error {
+			if got := c.String("env"); got != "prod" {
+				t.Errorf(`expected "prod", got %q`, got)
+			}
+			if got := c.String("tag"); got != "v1" {
+				t.Errorf(`expected "v1", got %q`, got)
+			}
+			if source, _ := c.FlagSource("env"); source.Kind != FlagSourceInteractive {
+				t.Errorf("expected the env flag to come from the prompt, got %s", source)
+			}
+			if source, _ := c.FlagSource("tag"); source.Kind != FlagSourceProgrammatic {
+				t.Errorf("expected the tag flag to be set by the program, got %s", source)
+			}
+			if got := c.Args().Get("project"); got != "website" {
+				t.Errorf(`expected "website", got %q`, got)
+			}
+			if got := c.Args().Tail(); !reflect.DeepEqual(got, []string{"a b", "c"}) {
+				t.Errorf(`expected ["a b" "c"], got %q`, got)
+			}
+			return nil
+		},
+	}
+
+	set := flag.NewFlagSet("test", 0)
+	if err := set.Parse([]string{"deploy"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := command.Run(NewContext(app, set, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if !interactCalled {
+		t.Error("expected Interact to be called")
+	}
+}
+
+func TestCommandInteract_NoInteraction(t *testing.T) {
+	terminal.Stdin.SetInteractive(false)
+
+	app := &Application{
+		Writer: io.Discard,
+		Commands: []*Command{
+			{
+				Name:  "deploy",
+				Flags: []Flag{&StringFlag{Name: "env", Required: true}},
+				Interact: func(c *Context) error {
+					t.Error("Interact should not be called when the input is not interactive")
+					return nil
+				},
+				Action: func(c *Context) error {
+					t.Error("the command should not be executed")
+					return nil
+				},
+			},
+		},
+	}
+
+	_, err := app.Execute([]string{"app", "deploy", "--no-interaction"})
+	if err == nil || !strings.Contains(err.Error(), `Required flag "env" is not set`) {
+		t.Errorf("expected a required flag error, got %v", err)
+	}
+}
+
+func TestContextSetArg(t *testing.T) {
+	set := flag.NewFlagSet("test", 0)
+	if err := set.Parse([]string{}); err != nil {
+		t.Fatal(err)
+	}
+	ctx := NewContext(nil, set, nil)
+	ctx.Command = &Command{
+		Args: ArgDefinition{
+			{Name: "first", Default: "one"},
+			{Name: "second"},
+			{Name: "rest", Slice: true},
+		},
+	}
+
+	if err := ctx.SetArg("second", "--two"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ctx.SetArg("rest", "three", "four"); err != nil {
+		t.Fatal(err)
+	}
+	if got := ctx.Args().Slice(); !reflect.DeepEqual(got, []string{"one", "--two", "three", "four"}) {
+		t.Errorf("unexpected args %q", got)
+	}
+	if err := ctx.SetArg("first", "a", "b"); err == nil {
+		t.Error("expected an error when setting several values to a single argument")
+	}
+	if err := ctx.SetArg("unknown", "a"); err == nil {
+		t.Error("expected an error for an unknown argument")
+	}
+}
+
+func TestCommandInteract_Help(t *testing.T) {
+	resetOutputsInput()
+	defer resetOutputsInput()
+	terminal.Stdin.SetInteractive(true)
+	defer terminal.Stdin.SetInteractive(false)
+	terminal.Stdin.SetReader(iotest.OneByteReader(stdin))
+	defer terminal.Stdin.SetReader(stdin)
+
+	stdin.WriteString("prod\nwebsite\n")
+
+	app := &Application{Writer: io.Discard}
+	app.setup()
+	command := &Command{
+		Name:  "deploy",
+		Flags: []Flag{&StringFlag{Name: "env", Required: true}},
+		Args:  ArgDefinition{{Name: "project"}},
+		Interact: func(c *Context) error {
+			t.Error("Interact should not be called when the help is asked")
+			return nil
+		},
+		Action: func(c *Context) error {
+			t.Error("the command should not be executed")
+			return nil
+		},
+	}
+
+	set := flag.NewFlagSet("test", 0)
+	if err := set.Parse([]string{"deploy", "--help"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := command.Run(NewContext(app, set, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if stdin.Len() != len("prod\nwebsite\n") {
+		t.Error("expected nothing to be asked")
+	}
+}
+
+func TestCommandInteract_Sensitive(t *testing.T) {
+	resetOutputsInput()
+	defer resetOutputsInput()
+	terminal.Stdin.SetInteractive(true)
+	defer terminal.Stdin.SetInteractive(false)
+	terminal.Stdin.SetReader(iotest.OneByteReader(stdin))
+	defer terminal.Stdin.SetReader(stdin)
+
+	stdin.WriteString("s3cr3t\n")
+
+	var password string
+	app := &Application{Writer: io.Discard}
+	app.setup()
+	command := &Command{
+		Name: "login",
+		Flags: []Flag{
+			&StringFlag{Name: "password", Required: true, Sensitive: true},
+		},
+		Action: func(c *Context) error {
+			password = c.String("password")
+			return nil
+		},
+	}
+
+	set := flag.NewFlagSet("test", 0)
+	if err := set.Parse([]string{"login"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := command.Run(NewContext(app, set, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if password != "s3cr3t" {
+		t.Errorf(`expected "s3cr3t", got %q`, password)
+	}
+	if got := app.sensitive.redact("invalid password s3cr3t"); got != "invalid password ******" {
+		t.Errorf("expected the answer to be masked, got %q", got)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/pkg/errors"
 	"github.com/posener/complete"
@@ -90,6 +91,10 @@ type verbosityFlag struct {
 	Destination  *logLevelValue
 }
 
+// verbosityFlagMutex protects the fields updated when a verbosity flag is
+// applied, as the flag is shared by all applications by default
+var verbosityFlagMutex sync.Mutex
+
 func VerbosityFlag(name, alias, shortAlias string) *verbosityFlag {
 	return &verbosityFlag{
 		Name:        name,
@@ -109,11 +114,14 @@ func (f *verbosityFlag) Validate(c *Context) error {
 }
 
 func (f *verbosityFlag) Apply(set *flag.FlagSet) {
+	destination := &logLevelValue{}
+	verbosityFlagMutex.Lock()
 	f.DefaultValue = terminal.GetLogLevel()
-	f.Destination = &logLevelValue{}
+	f.Destination = destination
+	verbosityFlagMutex.Unlock()
 
 	if f.Name != "" {
-		set.Var(f.Destination, f.Name, f.Usage)
+		set.Var(destination, f.Name, f.Usage)
 	}
 
 	for _, alias := range f.Aliases {
@@ -24,6 +24,7 @@ import (
 	"io"
 	"os"
 	"strconv"
+	"sync"
 
 	"github.com/pkg/errors"
 	"github.com/posener/complete"
@@ -123,17 +124,27 @@ var (
 		Name:  "no-interaction",
 		Usage: "Disable all interactions",
 	}
+	// Deprecated: --no-ansi is the negated form of AnsiFlag, this flag is
+	// not registered anymore
 	NoAnsiFlag = &BoolFlag{
 		Name:  "no-ansi",
 		Usage: "Disable ANSI output",
 	}
 	AnsiFlag = &BoolFlag{
-		Name:  "ansi",
-		Usage: "Force ANSI output",
+		Name:      "ansi",
+		Usage:     "Force (or disable --no-ansi) ANSI output",
+		Negatable: true,
 	}
 )
 
+// ioMutex serializes the configuration of the terminal, which is global, when
+// several applications run concurrently
+var ioMutex sync.Mutex
+
 func (app *Application) configureIO(c *Context) {
+	ioMutex.Lock()
+	defer ioMutex.Unlock()
+
 	if IsAutocomplete(c.Command) {
 		terminal.DefaultStdout.SetDecorated(false)
 		terminal.Stdin.SetInteractive(false)
@@ -142,8 +153,6 @@ func (app *Application) configureIO(c *Context) {
 
 	if c.IsSet(AnsiFlag.Name) {
 		terminal.DefaultStdout.SetDecorated(c.Bool(AnsiFlag.Name))
-	} else if c.IsSet(NoAnsiFlag.Name) {
-		terminal.DefaultStdout.SetDecorated(!c.Bool(NoAnsiFlag.Name))
 	} else if _, isPresent := os.LookupEnv("NO_COLOR"); isPresent {
 		terminal.DefaultStdout.SetDecorated(false)
 	}
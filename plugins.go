@@ -0,0 +1,205 @@
+/*
+ * Copyright (c) 2021-present Fabien Potencier <fabien@symfony.com>
+ *
+ * This file is part of Symfony CLI project
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package console
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"github.com/symfony-cli/terminal"
+)
+
+// PluginCategory is the category under which external plugin commands are
+// registered
+const PluginCategory = "plugins"
+
+// loadPlugins registers a command for each executable found in $PATH whose
+// name starts with Application.PluginPrefix. Built-in commands always take
+// precedence over plugins. As scanning $PATH is costly, it is only done when
+// a command cannot be found, when listing commands and for completion.
+func (a *Application) loadPlugins() {
+	a.pluginsOnce.Do(func() {
+		if a.PluginPrefix == "" {
+			return
+		}
+
+		for _, plugin := range findPlugins(a.PluginPrefix) {
+			if a.Command(plugin.name) != nil {
+				continue
+			}
+			c := newPluginCommand(plugin.name, plugin.path)
+			a.Commands = append(a.Commands, c)
+			a.setupCommand(c)
+			if a.Categories != nil {
+				a.Categories.AddCommand(c.namespace(), c)
+			}
+		}
+		if categories, ok := a.Categories.(*commandCategories); ok {
+			sort.Sort(categories)
+		}
+	})
+}
+
+type plugin struct {
+	name, path string
+}
+
+// findPlugins looks for executables starting with prefix in $PATH, the first
+// one found wins when the same name is present in several directories
+func findPlugins(prefix string) []plugin {
+	plugins := []plugin{}
+	seen := make(map[string]bool)
+
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			filename := entry.Name()
+			if entry.IsDir() || !strings.HasPrefix(filename, prefix) {
+				continue
+			}
+			name := strings.TrimPrefix(filename, prefix)
+			if runtime.GOOS == "windows" {
+				if ext := strings.ToLower(filepath.Ext(name)); ext != ".exe" && ext != ".bat" && ext != ".cmd" {
+					continue
+				}
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			} else if info, err := entry.Info(); err != nil || info.Mode()&0111 == 0 {
+				continue
+			}
+			name = strings.ToLower(name)
+			if name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			plugins = append(plugins, plugin{name: name, path: filepath.Join(dir, filename)})
+		}
+	}
+
+	return plugins
+}
+
+func newPluginCommand(name, path string) *Command {
+	return &Command{
+		Category:    PluginCategory,
+		Name:        name,
+		Aliases:     []*Alias{{Name: name}},
+		Usage:       fmt.Sprintf("Run the %s plugin", filepath.Base(path)),
+		FlagParsing: FlagParsingSkipped,
+		Args: ArgDefinition{
+			&Arg{Name: "args", Slice: true, Optional: true},
+		},
+		Action: func(c *Context) error {
+			cmd := exec.Command(path, c.rawArgs().Slice()...)
+			cmd.Stdin = terminal.Stdin
+			cmd.Stdout = c.App.Writer
+			cmd.Stderr = c.App.ErrWriter
+			// plugins are given the standard streams directly when possible
+			// for them to keep their terminal and for their output not to
+			// be formatted
+			if terminal.Stdin.IsInteractive() && terminal.Stdin.Fd() == os.Stdin.Fd() {
+				cmd.Stdin = os.Stdin
+			}
+			if isStream(c.App.Writer, os.Stdout) {
+				cmd.Stdout = os.Stdout
+			}
+			if isStream(c.App.ErrWriter, os.Stderr) {
+				cmd.Stderr = os.Stderr
+			}
+
+			if err := cmd.Start(); err != nil {
+				return errors.WithStack(err)
+			}
+			// an interrupt typed in the terminal is already received by the
+			// plugin
+			stop := forwardSignals(cmd.Process, cmd.Stdin == os.Stdin)
+			err := cmd.Wait()
+			stop()
+			if err != nil {
+				var exitErr *exec.ExitError
+				if errors.As(err, &exitErr) {
+					return Exit("", pluginExitCode(exitErr))
+				}
+				return errors.WithStack(err)
+			}
+
+			return nil
+		},
+	}
+}
+
+// isStream reports whether w writes to file
+func isStream(w io.Writer, file *os.File) bool {
+	fd, ok := w.(terminal.FdHolder)
+	return ok && fd.Fd() == file.Fd()
+}
+
+// forwardSignals sends SIGINT and SIGTERM to the plugin process for it to
+// be able to clean up before exiting, the returned function stops the
+// forwarding
+func forwardSignals(p *os.Process, skipInterrupt bool) func() {
+	signals := make(chan os.Signal, 2)
+	done := make(chan struct{})
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				if sig != os.Interrupt || !skipInterrupt {
+					_ = p.Signal(sig)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+// pluginExitCode returns the exit code of the plugin, 128+signal when it was
+// killed by a signal
+func pluginExitCode(err *exec.ExitError) int {
+	if code := err.ExitCode(); code >= 0 {
+		return code
+	}
+	if status, ok := err.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+	return 1
+}
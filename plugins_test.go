@@ -0,0 +1,158 @@
+/*
+ * Copyright (c) 2021-present Fabien Potencier <fabien@symfony.com>
+ *
+ * This file is part of Symfony CLI project
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package console
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestPluginCommands(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin scripts are not supported on Windows")
+	}
+
+	dir := t.TempDir()
+	output := filepath.Join(dir, "output")
+	script := "#!/bin/sh\necho \"$@\" > " + output + "\nexit 5\n"
+	if err := os.WriteFile(filepath.Join(dir, "app-hello"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "app-foo"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "app-greet"), []byte("#!/bin/sh\necho \"hello $1\"\necho oops >&2\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "app-notexecutable"), []byte(script), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+
+	builtinCalled := false
+	buf := new(bytes.Buffer)
+	errBuf := new(bytes.Buffer)
+	app := &Application{
+		Writer:       buf,
+		ErrWriter:    errBuf,
+		PluginPrefix: "app-",
+		Commands: []*Command{
+			{
+				Name: "foo",
+				Action: func(c *Context) error {
+					builtinCalled = true
+					return nil
+				},
+			},
+		},
+	}
+
+	if _, err := app.Execute([]string{"app", "foo"}); err != nil || !builtinCalled {
+		t.Errorf("expected the built-in command to take precedence, got %v", err)
+	}
+	for _, args := range [][]string{{"app", "foo", "--help"}, {"app", "help", "foo"}, {"app", "foo", "--unknown"}} {
+		_, _ = app.Execute(args)
+	}
+	buf.Reset()
+	if app.Command("hello") != nil {
+		t.Error("expected plugins not to be looked for when the command exists")
+	}
+
+	exitCode, err := app.Execute([]string{"app", "hello", "--name", "world", "--", "arg"})
+	if exitCode != 5 || err == nil {
+		t.Fatalf("expected the plugin exit code, got %d and %v", exitCode, err)
+	}
+	if got, _ := os.ReadFile(output); string(got) != "--name world -- arg\n" {
+		t.Errorf("expected the plugin to receive the arguments, got %q", got)
+	}
+
+	builtinCalled = false
+	if _, err := app.Execute([]string{"app", "foo"}); err != nil || !builtinCalled {
+		t.Errorf("expected the built-in command to take precedence over plugins, got %v", err)
+	}
+
+	if _, err := app.Execute([]string{"app", "greet", "world"}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "hello world\n" || errBuf.String() != "oops\n" {
+		t.Errorf("expected the plugin to write to the application outputs, got %q and %q", buf.String(), errBuf.String())
+	}
+
+	if app.Command("notexecutable") != nil {
+		t.Error("expected non executable files to be ignored")
+	}
+
+	if _, err := app.Execute([]string{"app", "list"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "<comment>plugins</>") || !strings.Contains(buf.String(), "<info>plugins:hello, hello</>") {
+		t.Errorf("expected plugins to be listed, got %q", buf.String())
+	}
+}
+
+func TestPluginSignals(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin scripts are not supported on Windows")
+	}
+
+	dir := t.TempDir()
+	ready := filepath.Join(dir, "ready")
+	output := filepath.Join(dir, "output")
+	script := "#!/bin/sh\ntrap 'echo cleaned up > " + output + "; exit 3' TERM\ntouch " + ready + "\nwhile :; do sleep 0.1; done\n"
+	if err := os.WriteFile(filepath.Join(dir, "app-wait"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "app-killed"), []byte("#!/bin/sh\nkill -9 $$\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// the scripts need touch and sleep
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	app := &Application{Writer: io.Discard, PluginPrefix: "app-"}
+
+	go func() {
+		for {
+			if _, err := os.Stat(ready); err == nil {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		p, _ := os.FindProcess(os.Getpid())
+		_ = p.Signal(syscall.SIGTERM)
+	}()
+	exitCode, _ := app.Execute([]string{"app", "wait"})
+	if got, _ := os.ReadFile(output); string(got) != "cleaned up\n" {
+		t.Errorf("expected the plugin to handle the signal, got %q", got)
+	}
+	if exitCode != 3 {
+		t.Errorf("expected the plugin exit code, got %d", exitCode)
+	}
+
+	if exitCode, _ := app.Execute([]string{"app", "killed"}); exitCode != 128+9 {
+		t.Errorf("expected 128+signal as exit code, got %d", exitCode)
+	}
+}
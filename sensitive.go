@@ -0,0 +1,149 @@
+/*
+ * Copyright (c) 2021-present Fabien Potencier <fabien@symfony.com>
+ *
+ * This file is part of Symfony CLI project
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package console
+
+import (
+	"flag"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// redactedValue replaces the values of sensitive flags
+const redactedValue = "******"
+
+// sensitiveValues holds the values given to sensitive flags of an
+// application, they are masked in error messages and traces
+type sensitiveValues struct {
+	sync.RWMutex
+	values map[string]bool
+}
+
+// flagIsSensitive reports whether the values of the flag must be masked
+func flagIsSensitive(f Flag) bool {
+	field := flagValue(f).FieldByName("Sensitive")
+	if field.IsValid() && field.Kind() == reflect.Bool {
+		return field.Bool()
+	}
+
+	return false
+}
+
+func (s *sensitiveValues) register(value string) {
+	if value == "" {
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	if s.values == nil {
+		s.values = make(map[string]bool)
+	}
+	s.values[value] = true
+}
+
+// registerArgs registers the values given on the command line to sensitive
+// flags, before they are parsed as parsing errors contain them. Arguments must
+// have been fixed first for abbreviations and clusters to be expanded.
+func (s *sensitiveValues) registerArgs(flags []Flag, args []string) {
+	for i, arg := range args {
+		if arg == "--" {
+			return
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		f := findFlag(flags, name)
+		if f == nil || !flagIsSensitive(f) || !flagTakesValue(f) {
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				continue
+			}
+			value = args[i+1]
+		}
+		s.register(value)
+		// key=value pairs of map flags
+		if _, isMap := f.(*StringMapFlag); isMap {
+			if _, v, ok := strings.Cut(value, "="); ok {
+				s.register(v)
+			}
+		}
+	}
+}
+
+// registerFlagValues registers the values of the sensitive flags which have
+// been set
+func (s *sensitiveValues) registerFlagValues(flags []Flag, fs *flag.FlagSet) {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	for _, f := range flags {
+		if !flagIsSensitive(f) || !flagTakesValue(f) || !set[flagName(f)] {
+			continue
+		}
+		value := fs.Lookup(flagName(f)).Value
+		var v interface{} = value.String()
+		if getter, ok := value.(flag.Getter); ok {
+			v = getter.Get()
+		}
+		switch v := v.(type) {
+		case string:
+			s.register(v)
+		case []string:
+			for _, value := range v {
+				s.register(value)
+			}
+		case map[string]string:
+			for _, value := range v {
+				s.register(value)
+			}
+		default:
+			s.register(value.String())
+		}
+	}
+}
+
+// redact masks the registered values in str
+func (s *sensitiveValues) redact(str string) string {
+	if s == nil {
+		return str
+	}
+
+	s.RLock()
+	values := make([]string, 0, len(s.values))
+	for value := range s.values {
+		values = append(values, value)
+	}
+	s.RUnlock()
+
+	// longest values first as they might contain shorter ones
+	sort.Slice(values, func(i, j int) bool {
+		return len(values[i]) > len(values[j])
+	})
+	for _, value := range values {
+		str = strings.ReplaceAll(str, value, redactedValue)
+	}
+	return str
+}
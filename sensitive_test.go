@@ -0,0 +1,130 @@
+/*
+ * Copyright (c) 2021-present Fabien Potencier <fabien@symfony.com>
+ *
+ * This file is part of Symfony CLI project
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package console
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestSensitiveFlagHelp(t *testing.T) {
+	for _, test := range []struct {
+		flag     Flag
+		expected string
+	}{
+		{&StringFlag{Name: "token", DefaultValue: "d3f4ult-t0k3n", Sensitive: true}, "<info>--token=value</>\t<comment>[default: ******]</>"},
+		{&StringFlag{Name: "token", DefaultText: "from the keychain", Sensitive: true}, "<info>--token=value</>\t<comment>[default: from the keychain]</>"},
+		{&StringFlag{Name: "token", Sensitive: true}, "<info>--token=value</>\t"},
+		{&StringSliceFlag{Name: "key", Destination: NewStringSlice("k1", "k2"), Sensitive: true}, "<info>--key=value</>\t<comment>[default: ******]</>"},
+		{&StringMapFlag{Name: "secret", Destination: NewStringMap(map[string]string{"API_KEY": "xyz"}), Sensitive: true}, "<info>--secret=key=value</>\t<comment>[default: \"API_KEY=******\"]</>"},
+	} {
+		if got := test.flag.String(); got != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, got)
+		}
+	}
+}
+
+func TestSensitiveFlagValuesRedaction(t *testing.T) {
+	t.Setenv("TEST_SENSITIVE_TOKEN", "env-t0k3n-value")
+
+	app := &Application{
+		Writer: io.Discard,
+		Flags: []Flag{
+			&StringFlag{Name: "token", EnvVars: []string{"TEST_SENSITIVE_TOKEN"}, Sensitive: true},
+			&IntFlag{Name: "pin", Aliases: []string{"p"}, Sensitive: true},
+			&StringMapFlag{Name: "secret", Sensitive: true},
+			&StringFlag{Name: "user"},
+		},
+		Action: func(c *Context) error {
+			return errors.Errorf("cannot log in %s with %s and %v", c.String("user"), c.String("token"), c.StringMap("secret"))
+		},
+	}
+
+	_, err := app.Execute([]string{"app", "--user=fabien", "--secret", "API_KEY=m4p-s3cr3t"})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if got, expected := app.sensitive.redact(err.Error()), "cannot log in fabien with ****** and map[API_KEY:******]"; got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	var buf bytes.Buffer
+	if !formatErrorChain(&buf, err, true, &app.sensitive) {
+		t.Fatal("expected the error to have a stack trace")
+	}
+	if strings.Contains(buf.String(), "env-t0k3n-value") || strings.Contains(buf.String(), "m4p-s3cr3t") {
+		t.Errorf("expected sensitive values to be redacted, got %q", buf.String())
+	}
+
+	// abbreviations and clusters are expanded before values are registered
+	for _, test := range []struct {
+		args  []string
+		value string
+	}{
+		{[]string{"app", "--pin=98x76"}, "98x76"},
+		{[]string{"app", "--pi=12y34"}, "12y34"},
+		{[]string{"app", "-p56z78"}, "56z78"},
+		{[]string{"app", "-p", "x"}, "x"},
+	} {
+		_, err = app.Execute(test.args)
+		if err == nil || !strings.Contains(err.Error(), test.value) {
+			t.Fatalf("expected a parse error for %v, got %v", test.args, err)
+		}
+		if got := app.sensitive.redact(err.Error()); strings.Contains(got, `"`+test.value+`"`) {
+			t.Errorf("expected the invalid value of %v to be redacted, got %q", test.args, got)
+		}
+	}
+}
+
+func TestSensitiveValuesScope(t *testing.T) {
+	newApp := func() *Application {
+		return &Application{
+			Writer: io.Discard,
+			Flags: []Flag{
+				&StringFlag{Name: "token", Sensitive: true},
+				&IntFlag{Name: "level", Sensitive: true},
+				&StringSliceFlag{Name: "label", Sensitive: true},
+			},
+			Action: func(c *Context) error {
+				return nil
+			},
+		}
+	}
+
+	app := newApp()
+	if _, err := app.Execute([]string{"app", "--token=s3cr3t-t0k3n", "--level=1", "--label", "env=prod"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, expected := app.sensitive.redact("s3cr3t-t0k3n at level 1 of env=prod"), "****** at level ****** of ******"; got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+	// only map flags hold key=value pairs
+	if got, expected := app.sensitive.redact("prod"), "prod"; got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	if got, expected := newApp().sensitive.redact("s3cr3t-t0k3n"), "s3cr3t-t0k3n"; got != expected {
+		t.Errorf("expected values not to leak between applications, got %q", got)
+	}
+}
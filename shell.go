@@ -0,0 +1,414 @@
+/*
+ * Copyright (c) 2021-present Fabien Potencier <fabien@symfony.com>
+ *
+ * This file is part of Symfony CLI project
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package console
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"github.com/posener/complete"
+	"github.com/symfony-cli/terminal"
+	"golang.org/x/term"
+)
+
+var shellCommand = &Command{
+	Category: "self",
+	Name:     "shell",
+	Aliases:  []*Alias{{Name: "shell"}},
+	Usage:    "Start an interactive shell to run the application commands",
+	Description: `The <info>{{.HelpName}}</> command starts an interactive shell where
+application commands can be run one after the other without their name being
+prefixed by the application name:
+
+  <info>{{.HelpName}}</>
+  > help
+  > version
+
+Global options given when starting the shell apply to all the commands.
+Type <comment>exit</> or press <comment>Ctrl+D</> to quit.`,
+	Action: ShellAction,
+}
+
+func registerShellCommand(a *Application) {
+	if !a.EnableShell || shellCommand.isHidden() {
+		return
+	}
+	for _, name := range shellCommand.Names() {
+		if a.Command(name) != nil {
+			return
+		}
+	}
+
+	a.Commands = append([]*Command{shellCommand.clone()}, a.Commands...)
+}
+
+// ShellAction reads commands line by line and runs them until the input is
+// exhausted. Errors are displayed but do not stop the shell.
+func ShellAction(c *Context) error {
+	// each line handles its own signals so an interruption only stops the
+	// current command, the shell is stopped by the ones received while
+	// waiting for a line
+	for cur := c; cur != nil; cur = cur.parentContext {
+		if cur.stopSignals != nil {
+			cur.stopSignals()
+		}
+	}
+	ctx, cancel := context.WithCancel(c.Context())
+	defer cancel()
+	c.ctx = ctx
+	var signals chan os.Signal
+	if !c.App.DisableSignalHandling {
+		signals = make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		defer signal.Stop(signals)
+	}
+
+	reader := newShellReader(c)
+	defer reader.Close()
+
+	for {
+		if err := c.Context().Err(); err != nil {
+			return errors.WithStack(err)
+		}
+
+		line, err := readShellLine(ctx, reader, signals, cancel)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return errors.WithStack(err)
+		}
+
+		args, err := splitCommandLine(line)
+		if err != nil {
+			handleError(err, &c.App.sensitive)
+			continue
+		}
+		if len(args) == 0 {
+			continue
+		}
+		if args[0] == "exit" || args[0] == "quit" {
+			return nil
+		}
+
+		handleError(c.App.runShellLine(c, args), &c.App.sensitive)
+		// the signals received while the line was running were handled by
+		// its command
+		select {
+		case <-signals:
+		default:
+		}
+	}
+}
+
+// readShellLine reads a line from the reader, the session being canceled
+// when a signal is received or ctx is done in the meantime
+func readShellLine(ctx context.Context, reader shellReader, signals <-chan os.Signal, cancel context.CancelFunc) (string, error) {
+	type result struct {
+		line string
+		err  error
+	}
+	lines := make(chan result, 1)
+	go func() {
+		line, err := reader.ReadLine()
+		lines <- result{line, err}
+	}()
+
+	select {
+	case r := <-lines:
+		return r.line, r.err
+	case <-signals:
+		cancel()
+	case <-ctx.Done():
+	}
+	return "", errors.WithStack(ctx.Err())
+}
+
+// runShellLine runs a line of the shell like the application would do for a
+// command line, global IO settings changed by the line flags are restored
+// afterwards
+func (a *Application) runShellLine(shellCtx *Context, args []string) (err error) {
+	logLevel := terminal.GetLogLevel()
+	stdout, stderr, interactive := terminal.Stdout, terminal.Stderr, terminal.Stdin.IsInteractive()
+	writer, errWriter := a.Writer, a.ErrWriter
+	defer func() {
+		_ = terminal.SetLogLevel(logLevel)
+		terminal.Stdout, terminal.Stderr = stdout, stderr
+		terminal.Stdin.SetInteractive(interactive)
+		a.Writer, a.ErrWriter = writer, errWriter
+	}()
+
+	ctx, cancel := context.WithCancel(shellCtx.Context())
+	defer cancel()
+
+	lineCtx := NewContext(a, nil, shellCtx)
+	lineCtx.ctx = ctx
+
+	defer func() {
+		if e := recover(); e != nil {
+			err = WrapPanic(e)
+		}
+		_, err = a.dispatchTerminate(lineCtx, err)
+	}()
+
+	if !a.DisableSignalHandling {
+		defer a.handleSignals(lineCtx, cancel)()
+	}
+
+	return a.run(lineCtx, append([]string{a.HelpName}, args...))
+}
+
+// inheritFlagValues makes the flags not set in the context use the value they
+// have in the parent execution (e.g. the global options of the shell)
+func inheritFlagValues(ctx *Context) {
+	set := make(map[string]bool)
+	ctx.flagSet.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	ctx.flagSet.VisitAll(func(f *flag.Flag) {
+		if set[f.Name] || !ctx.parentContext.IsSet(f.Name) {
+			return
+		}
+		if parentFlag := lookupRawFlag(f.Name, ctx.parentContext); parentFlag != nil {
+			f.Value = parentFlag.Value
+			if source, ok := ctx.parentContext.FlagSource(f.Name); ok {
+				ctx.flagSources[f.Name] = source
+			}
+		}
+	})
+}
+
+type shellReader interface {
+	ReadLine() (string, error)
+	Close() error
+}
+
+func newShellReader(c *Context) shellReader {
+	if fd := int(terminal.Stdin.Fd()); terminal.Stdin.IsInteractive() && term.IsTerminal(fd) {
+		return newTerminalShellReader(c, fd)
+	}
+
+	return &bufferedShellReader{bufio.NewReader(terminal.Stdin)}
+}
+
+// bufferedShellReader reads lines from a non-interactive input
+type bufferedShellReader struct {
+	reader *bufio.Reader
+}
+
+func (r *bufferedShellReader) ReadLine() (string, error) {
+	line, err := r.reader.ReadString('\n')
+	if err == io.EOF {
+		if line == "" {
+			return "", err
+		}
+		err = nil
+	}
+	return strings.TrimRight(line, "\r\n"), errors.WithStack(err)
+}
+
+func (r *bufferedShellReader) Close() error {
+	return nil
+}
+
+// terminalShellReader reads lines from a terminal with history and
+// completion support
+type terminalShellReader struct {
+	fd       int
+	terminal *term.Terminal
+
+	mu    sync.Mutex
+	state *term.State
+}
+
+func newTerminalShellReader(c *Context, fd int) *terminalShellReader {
+	r := &terminalShellReader{
+		fd: fd,
+		terminal: term.NewTerminal(struct {
+			io.Reader
+			io.Writer
+		}{terminal.Stdin, c.App.Writer}, fmt.Sprintf("%s> ", c.App.HelpName)),
+	}
+	r.terminal.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+		return r.complete(c, line, pos)
+	}
+
+	fmt.Fprintln(c.App.Writer, "Type <info>help</> to list the available commands, <info>exit</> or <comment>Ctrl+D</> to quit.")
+
+	return r
+}
+
+func (r *terminalShellReader) ReadLine() (string, error) {
+	// the terminal is only in raw mode while reading, commands get a regular
+	// terminal
+	state, err := term.MakeRaw(r.fd)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	r.mu.Lock()
+	r.state = state
+	r.mu.Unlock()
+	defer r.restore()
+
+	line, err := r.terminal.ReadLine()
+	if err == io.EOF {
+		return "", err
+	}
+	return line, errors.WithStack(err)
+}
+
+// Close restores the terminal when the shell stops while reading a line
+func (r *terminalShellReader) Close() error {
+	r.restore()
+	return nil
+}
+
+func (r *terminalShellReader) restore() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.state != nil {
+		_ = term.Restore(r.fd, r.state)
+		r.state = nil
+	}
+}
+
+// complete completes the word under the cursor, the candidates are listed
+// when there are several of them
+func (r *terminalShellReader) complete(c *Context, line string, pos int) (string, int, bool) {
+	candidates, last := shellCompletions(c, line[:pos])
+	if len(candidates) == 0 {
+		return "", 0, false
+	}
+
+	completion := candidates[0]
+	if len(candidates) == 1 {
+		completion += " "
+	} else {
+		for _, candidate := range candidates[1:] {
+			for !strings.HasPrefix(candidate, completion) {
+				completion = completion[:len(completion)-1]
+			}
+		}
+		if completion == last {
+			fmt.Fprintf(r.terminal, "%s\n", strings.Join(candidates, "  "))
+			return "", 0, false
+		}
+	}
+
+	prefix := line[:pos-len(last)] + completion
+	return prefix + line[pos:], len(prefix), true
+}
+
+// shellCompletions returns the sorted completion candidates for the last
+// word of the given line along with this word
+func shellCompletions(c *Context, line string) ([]string, string) {
+	words := strings.Fields(line)
+	if line == "" || unicode.IsSpace(rune(line[len(line)-1])) {
+		words = append(words, "")
+	}
+
+	args := complete.Args{
+		All:       words,
+		Completed: words[:len(words)-1],
+		Last:      words[len(words)-1],
+	}
+	if len(args.Completed) > 0 {
+		args.LastCompleted = args.Completed[len(args.Completed)-1]
+	}
+
+	cmd := c.App.completeCommand(c, words)
+	candidates := []string{}
+	for _, candidate := range cmd.Predict(args) {
+		if strings.HasPrefix(candidate, args.Last) {
+			candidates = append(candidates, candidate)
+		}
+	}
+	sort.Strings(candidates)
+
+	return candidates, args.Last
+}
+
+// splitCommandLine splits a line into arguments like a POSIX shell would do,
+// supporting single quotes, double quotes and backslash escapes
+func splitCommandLine(line string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		inArg   bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			if quote == '"' && r != '"' && r != '\\' && r != '$' && r != '`' {
+				current.WriteRune('\\')
+			}
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, errors.Errorf("unterminated quote in %q", line)
+	}
+	if escaped {
+		current.WriteRune('\\')
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
@@ -0,0 +1,262 @@
+/*
+ * Copyright (c) 2021-present Fabien Potencier <fabien@symfony.com>
+ *
+ * This file is part of Symfony CLI project
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package console
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/symfony-cli/terminal"
+)
+
+func TestShell(t *testing.T) {
+	resetOutputsInput()
+	defer resetOutputsInput()
+	stdin.WriteString(`greet
+greet --name=Bob "and  friends"
+unknown-command
+fail
+
+greet 'again'`)
+
+	buf := new(bytes.Buffer)
+	greetings := 0
+	app := &Application{
+		Writer:      buf,
+		EnableShell: true,
+		Flags: []Flag{
+			&StringFlag{Name: "name", DefaultValue: "World"},
+		},
+		Commands: []*Command{
+			{
+				Name: "greet",
+				Args: ArgDefinition{{Name: "suffix", Optional: true}},
+				Action: func(c *Context) error {
+					greetings++
+					fmt.Fprintf(c.App.Writer, "Hello %s %s\n", c.String("name"), c.Args().Get("suffix"))
+					return nil
+				},
+			},
+			{
+				Name: "fail",
+				Action: func(c *Context) error {
+					return Exit("failure", 3)
+				},
+			},
+		},
+	}
+
+	exitCode, err := app.Execute([]string{"app", "--name=Alice", "shell"})
+	if err != nil || exitCode != 0 {
+		t.Fatalf("expected the shell to succeed, got %d and %v", exitCode, err)
+	}
+	if greetings != 3 {
+		t.Errorf("expected 3 greetings, got %d", greetings)
+	}
+	output := buf.String()
+	for _, expected := range []string{"Hello Alice \n", "Hello Bob and  friends\n", "Hello Alice again\n"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected output to contain %q, got %q", expected, output)
+		}
+	}
+}
+
+func TestShellOptIn(t *testing.T) {
+	app := &Application{}
+	app.setup()
+	if app.Command("shell") != nil || app.Command("self:shell") != nil {
+		t.Error("expected the shell command not to be registered by default")
+	}
+
+	app = &Application{EnableShell: true}
+	app.setup()
+	if app.Command("shell") == nil || app.Command("self:shell") == nil {
+		t.Error("expected the shell command to be registered")
+	}
+}
+
+func TestShellSignals(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sending signals is not supported on Windows")
+	}
+
+	// the line being read when the shell stops is only abandoned, the input
+	// is restored once it is done
+	r, w := io.Pipe()
+	eof := make(chan struct{})
+	terminal.Stdin.SetReader(&eofReader{r, eof})
+	defer func() {
+		w.Close()
+		<-eof
+		terminal.Stdin.SetReader(stdin)
+	}()
+
+	interrupt := func() {
+		p, err := os.FindProcess(os.Getpid())
+		if err == nil {
+			err = p.Signal(os.Interrupt)
+		}
+		if err != nil {
+			t.Error(err)
+		}
+	}
+
+	lineCanceled := make(chan bool, 1)
+	app := &Application{
+		Writer:      io.Discard,
+		EnableShell: true,
+		Commands: []*Command{
+			{
+				Name: "wait",
+				Action: func(c *Context) error {
+					interrupt()
+					select {
+					case <-c.Context().Done():
+						lineCanceled <- true
+					case <-time.After(5 * time.Second):
+						lineCanceled <- false
+					}
+					return nil
+				},
+			},
+		},
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := app.Execute([]string{"app", "shell"})
+		done <- err
+	}()
+
+	// an interruption while a line runs only stops its command
+	if _, err := io.WriteString(w, "wait\n"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case canceled := <-lineCanceled:
+		if !canceled {
+			t.Fatal("expected the command to be canceled on SIGINT")
+		}
+	case err := <-done:
+		t.Fatalf("expected the shell to keep running, got %v", err)
+	}
+
+	// an interruption while waiting for a line stops the shell
+	for {
+		select {
+		case err := <-done:
+			if err == nil {
+				t.Error("expected the shell to be canceled")
+			}
+			return
+		case <-time.After(100 * time.Millisecond):
+			interrupt()
+		}
+	}
+}
+
+// eofReader closes eof once the reader is exhausted
+type eofReader struct {
+	io.Reader
+	eof chan struct{}
+}
+
+func (r *eofReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if err == io.EOF {
+		close(r.eof)
+	}
+	return n, err
+}
+
+func TestShellCompletions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("completion is not supported on Windows")
+	}
+
+	app := &Application{
+		Flags: []Flag{
+			&StringFlag{Name: "name"},
+		},
+		Commands: []*Command{
+			{Name: "greet", Flags: []Flag{&BoolFlag{Name: "loud"}}},
+			{Name: "grant"},
+			{Category: "db", Name: "migrate"},
+		},
+	}
+	app.setup()
+	ctx := NewContext(app, nil, nil)
+
+	for line, expected := range map[string][]string{
+		"gr":          {"grant", "greet"},
+		"db:":         {"db:migrate"},
+		"greet --lou": {"--loud"},
+		"unknown":     {},
+	} {
+		if candidates, _ := shellCompletions(ctx, line); !reflect.DeepEqual(candidates, expected) {
+			t.Errorf("expected %v for %q, got %v", expected, line, candidates)
+		}
+	}
+}
+
+func TestTerminalShellReaderOutput(t *testing.T) {
+	buf := new(bytes.Buffer)
+	app := &Application{Name: "app", Writer: buf}
+	app.setup()
+
+	r := newTerminalShellReader(NewContext(app, nil, nil), 0)
+	buf.Reset()
+	if _, err := r.terminal.Write([]byte("hello\n")); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "hello") {
+		t.Errorf("expected the terminal to write to the application output, got %q", buf.String())
+	}
+}
+
+func TestSplitCommandLine(t *testing.T) {
+	for line, expected := range map[string][]string{
+		"":                          nil,
+		"  greet  ":                 {"greet"},
+		`greet "hello world" 'a b'`: {"greet", "hello world", "a b"},
+		`greet hello\ world`:        {"greet", "hello world"},
+		`greet "a \"b\" \c" ''`:     {"greet", `a "b" \c`, ""},
+		`greet 'it\'`:               {"greet", `it\`},
+		`greet --name="Bob Smith"`:  {"greet", "--name=Bob Smith"},
+	} {
+		args, err := splitCommandLine(line)
+		if err != nil {
+			t.Errorf("unexpected error for %q: %v", line, err)
+		} else if !reflect.DeepEqual(args, expected) {
+			t.Errorf("expected %q for %q, got %q", expected, line, args)
+		}
+	}
+
+	if _, err := splitCommandLine(`greet "hello`); err == nil || !strings.Contains(err.Error(), "unterminated quote") {
+		t.Errorf("expected an unterminated quote error, got %v", err)
+	}
+}
@@ -0,0 +1,171 @@
+/*
+ * Copyright (c) 2021-present Fabien Potencier <fabien@symfony.com>
+ *
+ * This file is part of Symfony CLI project
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package console
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	signatureTokenRegexp     = regexp.MustCompile(`\{\s*([^}]*?)\s*\}`)
+	signatureSeparatorRegexp = regexp.MustCompile(`\s+:\s+`)
+)
+
+// Define creates a command from a compact signature describing its name,
+// arguments and flags:
+//
+//	console.Define("deploy {env : Target env} {--force} {--tag=*}", action)
+//
+// The name can be prefixed by a category ("cloud:deploy"). Arguments are
+// required unless suffixed by "?" or given a default ("{env=prod}"), "*"
+// makes them accept several values ("{files*}", "{files?*}"). Flags without
+// a value are booleans, "{--tag=}" expects a value, "{--tag=latest}" defines
+// its default and "{--tag=*}" can be repeated. A shortcut can be given with
+// "{--t|tag=}". A description can follow any argument or flag after " : ".
+//
+// Define panics if the signature is invalid.
+func Define(signature string, action ActionFunc) *Command {
+	cmd, err := parseSignature(signature)
+	if err != nil {
+		panic(err.Error())
+	}
+	cmd.Action = action
+
+	return cmd
+}
+
+func parseSignature(signature string) (*Command, error) {
+	signature = strings.TrimSpace(signature)
+	cmd := &Command{}
+
+	name := signature
+	if i := strings.Index(signature, "{"); i != -1 {
+		name = strings.TrimSpace(signature[:i])
+	}
+	if name == "" || strings.ContainsAny(name, " \t\n") {
+		return nil, errors.Errorf(`invalid command name in signature "%s"`, signature)
+	}
+	if i := strings.LastIndex(name, ":"); i != -1 {
+		cmd.Category, cmd.Name = name[:i], name[i+1:]
+	} else {
+		cmd.Name = name
+	}
+
+	rest := signature[len(name):]
+	if strings.TrimSpace(signatureTokenRegexp.ReplaceAllString(rest, "")) != "" {
+		return nil, errors.Errorf(`unexpected content outside of braces in signature "%s"`, signature)
+	}
+
+	for _, match := range signatureTokenRegexp.FindAllStringSubmatch(rest, -1) {
+		token, description := match[1], ""
+		if parts := signatureSeparatorRegexp.Split(token, 2); len(parts) == 2 {
+			token, description = parts[0], strings.TrimSpace(parts[1])
+		}
+
+		if strings.HasPrefix(token, "--") {
+			f, err := parseSignatureFlag(strings.TrimPrefix(token, "--"), description)
+			if err != nil {
+				return nil, errors.Wrapf(err, `invalid signature "%s"`, signature)
+			}
+			cmd.Flags = append(cmd.Flags, f)
+			continue
+		}
+
+		arg, err := parseSignatureArg(token, description)
+		if err != nil {
+			return nil, errors.Wrapf(err, `invalid signature "%s"`, signature)
+		}
+		cmd.Args = append(cmd.Args, arg)
+	}
+
+	if err := validateArgsModes(cmd.Args); err != nil {
+		return nil, errors.Wrapf(err, `invalid signature "%s"`, signature)
+	}
+	names := map[string]bool{}
+	for _, f := range cmd.Flags {
+		for _, name := range f.Names() {
+			if names[name] {
+				return nil, errors.Errorf(`invalid signature "%s": flag "%s" is defined twice`, signature, name)
+			}
+			names[name] = true
+		}
+	}
+
+	return cmd, nil
+}
+
+func parseSignatureArg(token, description string) (*Arg, error) {
+	arg := &Arg{Description: description}
+
+	switch {
+	case strings.HasSuffix(token, "?*") || strings.HasSuffix(token, "*?"):
+		arg.Name, arg.Optional, arg.Slice = token[:len(token)-2], true, true
+	case strings.HasSuffix(token, "*"):
+		arg.Name, arg.Slice = token[:len(token)-1], true
+	case strings.HasSuffix(token, "?"):
+		arg.Name, arg.Optional = token[:len(token)-1], true
+	case strings.Contains(token, "="):
+		i := strings.Index(token, "=")
+		arg.Name, arg.Default, arg.Optional = token[:i], strings.TrimSpace(token[i+1:]), true
+	default:
+		arg.Name = token
+	}
+
+	if !isValidSignatureName(arg.Name) {
+		return nil, errors.Errorf(`invalid argument name "%s"`, arg.Name)
+	}
+
+	return arg, nil
+}
+
+func parseSignatureFlag(token, description string) (Flag, error) {
+	name, value, hasValue := token, "", false
+	if i := strings.Index(token, "="); i != -1 {
+		name, value, hasValue = token[:i], strings.TrimSpace(token[i+1:]), true
+	}
+
+	var aliases []string
+	if i := strings.Index(name, "|"); i != -1 {
+		aliases = []string{name[:i]}
+		name = name[i+1:]
+		if !isValidSignatureName(aliases[0]) {
+			return nil, errors.Errorf(`invalid flag shortcut "%s"`, aliases[0])
+		}
+	}
+	if !isValidSignatureName(name) {
+		return nil, errors.Errorf(`invalid flag name "%s"`, name)
+	}
+
+	switch {
+	case !hasValue:
+		return &BoolFlag{Name: name, Aliases: aliases, Usage: description}, nil
+	case value == "*":
+		return &StringSliceFlag{Name: name, Aliases: aliases, Usage: description}, nil
+	default:
+		return &StringFlag{Name: name, Aliases: aliases, Usage: description, DefaultValue: value}, nil
+	}
+}
+
+func isValidSignatureName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, " \t\n{}=|?*:") && !strings.HasPrefix(name, "-")
+}
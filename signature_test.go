@@ -0,0 +1,92 @@
+/*
+ * Copyright (c) 2021-present Fabien Potencier <fabien@symfony.com>
+ *
+ * This file is part of Symfony CLI project
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package console
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDefine(t *testing.T) {
+	called := false
+	cmd := Define(`cloud:deploy
+		{env : Target env}
+		{version=latest : The version}
+		{files?*}
+		{--force}
+		{--t|tag=* : Tags to apply}
+		{--message= : A message}
+		{--strategy=rolling}`, func(c *Context) error {
+		called = true
+		return nil
+	})
+
+	if cmd.Category != "cloud" || cmd.Name != "deploy" {
+		t.Errorf("unexpected name %q", cmd.FullName())
+	}
+	expectedArgs := ArgDefinition{
+		{Name: "env", Description: "Target env"},
+		{Name: "version", Default: "latest", Description: "The version", Optional: true},
+		{Name: "files", Optional: true, Slice: true},
+	}
+	if !reflect.DeepEqual(cmd.Args, expectedArgs) {
+		t.Errorf("unexpected args %#v", cmd.Args)
+	}
+	expectedFlags := []Flag{
+		&BoolFlag{Name: "force"},
+		&StringSliceFlag{Name: "tag", Aliases: []string{"t"}, Usage: "Tags to apply"},
+		&StringFlag{Name: "message", Usage: "A message"},
+		&StringFlag{Name: "strategy", DefaultValue: "rolling"},
+	}
+	if !reflect.DeepEqual(cmd.Flags, expectedFlags) {
+		t.Errorf("unexpected flags %#v", cmd.Flags)
+	}
+
+	if err := cmd.Action(nil); err != nil || !called {
+		t.Error("expected the action to be set")
+	}
+}
+
+func TestDefine_Invalid(t *testing.T) {
+	for signature, expected := range map[string]string{
+		"":                           "invalid command name",
+		"deploy env":                 "invalid command name",
+		"deploy {env} extra":         "unexpected content outside of braces",
+		"deploy {env?} {version}":    "Cannot add a required argument after an optional one.",
+		"deploy {files*} {version}":  "Cannot add an argument after an array argument.",
+		"deploy {env} {env?}":        `An argument with name "env" already exists.`,
+		"deploy {--force} {--force}": `flag "force" is defined twice`,
+		"deploy {--=value}":          `invalid flag name ""`,
+		"deploy {e nv}":              `invalid argument name "e nv"`,
+	} {
+		func() {
+			defer func() {
+				e := recover()
+				if e == nil {
+					t.Errorf("expected %q to panic", signature)
+				} else if msg, ok := e.(string); !ok || !strings.Contains(msg, expected) {
+					t.Errorf("expected %q to panic with %q, got %v", signature, expected, e)
+				}
+			}()
+			Define(signature, nil)
+		}()
+	}
+}
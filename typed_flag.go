@@ -0,0 +1,297 @@
+/*
+ * Copyright (c) 2021-present Fabien Potencier <fabien@symfony.com>
+ *
+ * This file is part of Symfony CLI project
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package console
+
+import (
+	"encoding"
+	"flag"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/posener/complete"
+)
+
+var (
+	errFlagParse = errors.New("parse error")
+	errFlagRange = errors.New("value out of range")
+)
+
+// TypedFlag is a flag holding a value of type T. Strings, booleans, numbers,
+// durations and types implementing encoding.TextUnmarshaler are parsed out of
+// the box, Parser and Formatter allow to support any other type:
+//
+//	&TypedFlag[*url.URL]{
+//		Name:      "endpoint",
+//		Parser:    url.Parse,
+//		Formatter: func(u *url.URL) string { return u.String() },
+//	}
+//
+// When FromFile is set, "@path" and "-" values are replaced by the content of
+// the file or of stdin, and a --<name>-file flag taking a path is registered.
+// Values of Sensitive flags are masked in the help and in error messages.
+//
+// BoolFlag, the slice flags, StringMapFlag and GenericFlag are not built on
+// TypedFlag: they can be negated or accumulate values, and their Destination
+// types are part of their API.
+type TypedFlag[T any] struct {
+	Name          string
+	Aliases       []string
+	Usage         string
+	EnvVars       []string
+	Hidden        bool
+	DefaultValue  T
+	DefaultText   string
+	Required      bool
+	FromFile      bool
+	Sensitive     bool
+	ArgsPredictor func(*Context, complete.Args) []string
+	Validator     func(*Context, T) error
+	Destination   *T
+	Parser        func(string) (T, error)
+	Formatter     func(T) string
+}
+
+// String returns a readable representation of this value
+// (for usage defaults)
+func (f *TypedFlag[T]) String() string {
+	return FlagStringer(f)
+}
+
+func (f *TypedFlag[T]) PredictArgs(c *Context, a complete.Args) []string {
+	if f.ArgsPredictor != nil {
+		return f.ArgsPredictor(c, a)
+	}
+	return []string{}
+}
+
+func (f *TypedFlag[T]) Validate(c *Context) error {
+	if f.Validator != nil {
+		v, _ := Get[T](c, f.Name)
+		return f.Validator(c, v)
+	}
+	return nil
+}
+
+// Apply populates the flag given the flag set and environment
+func (f *TypedFlag[T]) Apply(set *flag.FlagSet) {
+	target := f.Destination
+	if target == nil {
+		target = new(T)
+	}
+	*target = f.DefaultValue
+	set.Var(&typedValue[T]{target: target, parser: f.Parser, formatter: f.Formatter, fromFile: f.FromFile}, f.Name, f.Usage)
+	if f.FromFile {
+		set.Var(&fileFlagValue{set, f.Name}, f.Name+fileFlagSuffix, "")
+	}
+}
+
+// isBoolFlag reports whether the flag can be set without a value
+func (f *TypedFlag[T]) isBoolFlag() bool {
+	_, isBool := interface{}(f.DefaultValue).(bool)
+	return isBool
+}
+
+// Names returns the names of the flag
+func (f *TypedFlag[T]) Names() []string {
+	return flagNames(f)
+}
+
+// defaultText returns the default value as displayed in the help, an empty
+// string meaning there is none
+func (f *TypedFlag[T]) defaultText() string {
+	v := reflect.ValueOf(&f.DefaultValue).Elem()
+	switch {
+	case f.Formatter != nil:
+		if v.IsZero() {
+			return ""
+		}
+		return f.Formatter(f.DefaultValue)
+	case v.Kind() == reflect.String:
+		if v.String() == "" {
+			return ""
+		}
+		return fmt.Sprintf("%q", v.String())
+	case v.Kind() == reflect.Ptr, v.Kind() == reflect.Interface, v.Kind() == reflect.Slice, v.Kind() == reflect.Map, v.Kind() == reflect.Struct:
+		if v.IsZero() {
+			return ""
+		}
+	}
+	return fmt.Sprintf("%v", f.DefaultValue)
+}
+
+// typedValue is the flag.Value of a TypedFlag
+type typedValue[T any] struct {
+	target    *T
+	parser    func(string) (T, error)
+	formatter func(T) string
+	fromFile  bool
+	// readFile is true when the current value was read from a file or stdin
+	readFile bool
+}
+
+func (v *typedValue[T]) Set(s string) error {
+	if v.fromFile {
+		var err error
+		v.readFile = s == "-" || strings.HasPrefix(s, "@")
+		if s, err = readFlagValue(s); err != nil {
+			return err
+		}
+	}
+	if v.parser != nil {
+		parsed, err := v.parser(s)
+		if err != nil {
+			return err
+		}
+		*v.target = parsed
+		return nil
+	}
+	return parseFlagValue(v.target, s)
+}
+
+// IsBoolFlag allows boolean flags to be set without a value
+func (v *typedValue[T]) IsBoolFlag() bool {
+	_, isBool := interface{}(v.target).(*bool)
+	return isBool
+}
+
+func (v *typedValue[T]) valueReadFromFile() bool {
+	return v.readFile
+}
+
+func (v *typedValue[T]) Get() interface{} {
+	return *v.target
+}
+
+func (v *typedValue[T]) String() string {
+	// flag.FlagSet calls String on a zero value to find out default values
+	if v.target == nil {
+		return ""
+	}
+	if v.formatter != nil {
+		return v.formatter(*v.target)
+	}
+	return fmt.Sprint(*v.target)
+}
+
+// parseFlagValue parses s into target, a pointer to a value of one of the
+// types supported by default by TypedFlag
+func parseFlagValue(target interface{}, s string) error {
+	var err error
+	switch p := target.(type) {
+	case *string:
+		*p = s
+	case *bool:
+		*p, err = strconv.ParseBool(s)
+	case *int:
+		var v int64
+		v, err = strconv.ParseInt(s, 0, strconv.IntSize)
+		*p = int(v)
+	case *int64:
+		*p, err = strconv.ParseInt(s, 0, 64)
+	case *uint:
+		var v uint64
+		v, err = strconv.ParseUint(s, 0, strconv.IntSize)
+		*p = uint(v)
+	case *uint64:
+		*p, err = strconv.ParseUint(s, 0, 64)
+	case *float64:
+		*p, err = strconv.ParseFloat(s, 64)
+	case *time.Duration:
+		*p, err = time.ParseDuration(s)
+		if err != nil {
+			err = errFlagParse
+		}
+	case encoding.TextUnmarshaler:
+		err = p.UnmarshalText([]byte(s))
+	default:
+		return errors.Errorf("no parser defined for values of type %s", reflect.TypeOf(target).Elem())
+	}
+
+	// same errors as the flag package for numbers
+	if numErr, ok := err.(*strconv.NumError); ok {
+		switch numErr.Err {
+		case strconv.ErrSyntax:
+			return errFlagParse
+		case strconv.ErrRange:
+			return errFlagRange
+		}
+	}
+	return err
+}
+
+// FlagTypeError is returned by Get when the flag does not hold a value of
+// the requested type
+type FlagTypeError struct {
+	Name     string
+	Expected reflect.Type
+	Actual   reflect.Type
+}
+
+func (e *FlagTypeError) Error() string {
+	return fmt.Sprintf(`flag "%s" holds a value of type %v, not %v`, e.Name, e.Actual, e.Expected)
+}
+
+// Get looks up the value of a flag, it returns a *FlagTypeError when the flag
+// does not hold a value of type T
+func Get[T any](c *Context, name string) (T, error) {
+	var zero T
+	f := lookupRawFlag(name, c)
+	if f == nil {
+		return zero, errors.Errorf(`flag "%s" is not defined`, name)
+	}
+
+	var value interface{} = f.Value.String()
+	if getter, ok := f.Value.(flag.Getter); ok {
+		value = getter.Get()
+	}
+	if v, ok := value.(T); ok {
+		return v, nil
+	}
+	return zero, &FlagTypeError{
+		Name:     name,
+		Expected: reflect.TypeOf(&zero).Elem(),
+		Actual:   reflect.TypeOf(value),
+	}
+}
+
+// lookupFlagValue returns the value of a flag, parsing its string
+// representation when it does not hold a T, the zero value is returned when
+// the flag is not found or when its value cannot be parsed
+func lookupFlagValue[T any](c *Context, name string) T {
+	var v T
+	f := lookupRawFlag(name, c)
+	if f == nil {
+		return v
+	}
+	if getter, ok := f.Value.(flag.Getter); ok {
+		if typed, ok := getter.Get().(T); ok {
+			return typed
+		}
+	}
+	if err := parseFlagValue(&v, f.Value.String()); err != nil {
+		var zero T
+		return zero
+	}
+	return v
+}
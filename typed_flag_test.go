@@ -0,0 +1,172 @@
+/*
+ * Copyright (c) 2021-present Fabien Potencier <fabien@symfony.com>
+ *
+ * This file is part of Symfony CLI project
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package console
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+type byteSize int64
+
+func parseByteSize(s string) (byteSize, error) {
+	units := map[string]int64{"K": 1 << 10, "M": 1 << 20, "G": 1 << 30}
+	multiplier := int64(1)
+	if m, ok := units[strings.ToUpper(s[len(s)-1:])]; ok && len(s) > 1 {
+		multiplier, s = m, s[:len(s)-1]
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	return byteSize(n * multiplier), err
+}
+
+func TestTypedFlag(t *testing.T) {
+	var (
+		endpoint *url.URL
+		ip       net.IP
+		limit    byteSize
+		port     int
+		dryRun   bool
+	)
+	app := &Application{
+		Writer: io.Discard,
+		Commands: []*Command{
+			{
+				Name: "serve",
+				Flags: []Flag{
+					&TypedFlag[*url.URL]{Name: "endpoint", Parser: url.Parse},
+					&TypedFlag[net.IP]{Name: "ip"},
+					&TypedFlag[byteSize]{
+						Name:         "limit",
+						DefaultValue: 1 << 20,
+						Parser:       parseByteSize,
+						Formatter:    func(b byteSize) string { return fmt.Sprintf("%dK", b>>10) },
+					},
+					&IntFlag{Name: "port", DefaultValue: 8000},
+					&TypedFlag[bool]{Name: "dry-run", Aliases: []string{"n"}},
+				},
+				Action: func(c *Context) error {
+					var err error
+					if endpoint, err = Get[*url.URL](c, "endpoint"); err != nil {
+						return err
+					}
+					if ip, err = Get[net.IP](c, "ip"); err != nil {
+						return err
+					}
+					if limit, err = Get[byteSize](c, "limit"); err != nil {
+						return err
+					}
+					port = c.Int("port")
+					dryRun, _ = Get[bool](c, "dry-run")
+					return nil
+				},
+			},
+		},
+	}
+
+	if _, err := app.Execute([]string{"app", "serve", "--endpoint=https://example.com/api", "--ip=127.0.0.1", "--limit=2M", "--port=0x50"}); err != nil {
+		t.Fatal(err)
+	}
+	if endpoint == nil || endpoint.Host != "example.com" {
+		t.Errorf("unexpected endpoint %v", endpoint)
+	}
+	if !ip.Equal(net.IPv4(127, 0, 0, 1)) {
+		t.Errorf("unexpected IP %v", ip)
+	}
+	if limit != 2<<20 {
+		t.Errorf("unexpected limit %d", limit)
+	}
+	if port != 80 {
+		t.Errorf("unexpected port %d", port)
+	}
+
+	for _, args := range [][]string{{"app", "serve", "--dry-run"}, {"app", "serve", "-n", "--port=80"}} {
+		dryRun = false
+		if _, err := app.Execute(args); err != nil {
+			t.Fatal(err)
+		}
+		if !dryRun {
+			t.Errorf("%v: expected a bare boolean flag to be set", args)
+		}
+	}
+
+	if _, err := app.Execute([]string{"app", "serve", "--port=http"}); err == nil || !strings.Contains(err.Error(), `invalid value "http" for flag -port: parse error`) {
+		t.Errorf("expected a parse error, got %v", err)
+	}
+	if _, err := app.Execute([]string{"app", "serve", "--ip=localhost"}); err == nil || !strings.Contains(err.Error(), `invalid value "localhost" for flag -ip`) {
+		t.Errorf("expected a parse error, got %v", err)
+	}
+
+	f := app.Command("serve").Flags[2]
+	if expected := "<info>--limit=value</>\t<comment>[default: 1024K]</>"; f.String() != expected {
+		t.Errorf("expected %q, got %q", expected, f.String())
+	}
+	f = app.Command("serve").Flags[0]
+	if expected := "<info>--endpoint=value</>\t"; f.String() != expected {
+		t.Errorf("expected %q, got %q", expected, f.String())
+	}
+}
+
+func TestGet(t *testing.T) {
+	app := &Application{
+		Writer: io.Discard,
+		Flags: []Flag{
+			&StringFlag{Name: "name"},
+			&DurationFlag{Name: "timeout"},
+			&StringSliceFlag{Name: "tags"},
+		},
+		Action: func(c *Context) error {
+			if v, err := Get[string](c, "name"); err != nil || v != "foo" {
+				t.Errorf(`expected "foo", got %q (%v)`, v, err)
+			}
+			if v, err := Get[[]string](c, "tags"); err != nil || strings.Join(v, ",") != "a,b" {
+				t.Errorf(`expected [a b], got %q (%v)`, v, err)
+			}
+			if v, err := Get[int](c, "name"); err == nil || v != 0 {
+				t.Errorf("expected an error, got %v", v)
+			} else {
+				var typeErr *FlagTypeError
+				if !errors.As(err, &typeErr) || typeErr.Name != "name" {
+					t.Errorf("expected a *FlagTypeError, got %v", err)
+				}
+				if err.Error() != `flag "name" holds a value of type string, not int` {
+					t.Errorf("unexpected message %q", err)
+				}
+			}
+			if _, err := Get[string](c, "unknown"); err == nil {
+				t.Error("expected an error for an undefined flag")
+			}
+			if got := c.Duration("timeout"); got.String() != "1m30s" {
+				t.Errorf(`expected "1m30s", got %q`, got)
+			}
+			return nil
+		},
+	}
+
+	if _, err := app.Execute([]string{"app", "--name=foo", "--tags=a", "--tags=b", "--timeout=90s"}); err != nil {
+		t.Fatal(err)
+	}
+}